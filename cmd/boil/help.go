@@ -102,6 +102,11 @@ var helpTopics = HelpTopics{
 		Description: "Bast reference.",
 		Print:       printBast,
 	},
+	{
+		Topic:       "funcs",
+		Description: "Template function reference.",
+		Print:       printFuncs,
+	},
 	{
 		Topic:       "globals",
 		Description: "About global flags.",
@@ -127,6 +132,21 @@ var helpTopics = HelpTopics{
 		Description: "'info' command usage.",
 		Print:       printInfo,
 	},
+	{
+		Topic:       "validate",
+		Description: "'validate' command usage.",
+		Print:       printValidate,
+	},
+	{
+		Topic:       "lint",
+		Description: "'lint' command usage.",
+		Print:       printLint,
+	},
+	{
+		Topic:       "schema",
+		Description: "'schema' command usage.",
+		Print:       printSchema,
+	},
 	{
 		Topic:       "edit",
 		Description: "'edit' command usage.",
@@ -137,6 +157,16 @@ var helpTopics = HelpTopics{
 		Description: "'exec' command usage.",
 		Print:       printExec,
 	},
+	{
+		Topic:       "backup",
+		Description: "'backup' command usage.",
+		Print:       printBackup,
+	},
+	{
+		Topic:       "upgrade",
+		Description: "'upgrade' command usage.",
+		Print:       printUpgrade,
+	},
 }
 
 func printHelp() {
@@ -175,6 +205,22 @@ func printInfo() {
 	cmdline.PrintCommand(os.Stdout, cmdlineConfig, cmdlineConfig.Commands.Find("info"), 0)
 	fmt.Print(infoText)
 }
+
+func printValidate() {
+	cmdline.PrintCommand(os.Stdout, cmdlineConfig, cmdlineConfig.Commands.Find("validate"), 0)
+	fmt.Print(validateText)
+}
+
+func printLint() {
+	cmdline.PrintCommand(os.Stdout, cmdlineConfig, cmdlineConfig.Commands.Find("lint"), 0)
+	fmt.Print(lintText)
+}
+
+func printSchema() {
+	cmdline.PrintCommand(os.Stdout, cmdlineConfig, cmdlineConfig.Commands.Find("schema"), 0)
+	fmt.Print(schemaText)
+}
+
 func printEdit() {
 	cmdline.PrintCommand(os.Stdout, cmdlineConfig, cmdlineConfig.Commands.Find("edit"), 0)
 	fmt.Print(editText)
@@ -185,10 +231,24 @@ func printExec() {
 	fmt.Print(execText)
 }
 
+func printBackup() {
+	cmdline.PrintCommand(os.Stdout, cmdlineConfig, cmdlineConfig.Commands.Find("backup"), 0)
+	fmt.Print(backupText)
+}
+
+func printUpgrade() {
+	cmdline.PrintCommand(os.Stdout, cmdlineConfig, cmdlineConfig.Commands.Find("upgrade"), 0)
+	fmt.Print(upgradeText)
+}
+
 func printBast() {
 	fmt.Print(bastText)
 }
 
+func printFuncs() {
+	fmt.Print(funcsText)
+}
+
 func printMetafile() {
 	fmt.Print(metafileText)
 }
@@ -343,11 +403,180 @@ the files of 'config' template in the same output directory and executing
 
 Templates referenced by the group are executed after the parent template files
 and in the order as they are defined in the metafile.
+
+A group may include other groups of the same template by name in its "groups"
+field, i.e. a 'foo#all' group can include the 'config' group and add the
+'webui' template. Members of included groups are executed first.
+
+A group template is either a path string or an object with following fields:
+
+  template     Path of the child template, may address its group using '#'.
+  description  Member description.
+  optional     If true, the member is executed only if selected.
+  selected     If true, an optional member is selected by default.
+  vars         Variable values that override variables of the same names
+               when the member is executed. Its prompts for them are skipped.
+
+Optional members of a group are selected from a list when the group is
+executed. The selection is a variable named after the group path, so it can
+be given on the command line, i.e. '--var "foo#all=webui"'. Without prompts
+the members selected by default are executed.
+
+
+Partials
+
+Template files can share snippets defined in partials. A partial is a file in a
+'_partials' directory in the repository root, in a template directory or in
+any directory that contains templates:
+
+  /repository
+    /_partials
+      license.tmpl
+    /go
+      /_partials
+        package.tmpl
+      /foo
+        /_partials
+          header.tmpl
+        main.go
+        boil.json
+
+Partials are parsed into the template set of every executed template file, so
+a block defined in a partial with '{{define "license-header"}}' is used with 
+'{{template "license-header" .}}'. Each partial is also a template named by its
+path in the '_partials' directory without the extension, i.e. 'license'.
+
+Partials of the repository root are parsed first, then partials of each template
+the template extends, starting with the most distant one, and then partials of
+the template itself. Partials of a template are those of the directories from
+the repository root down to the template directory, i.e. '_partials',
+'go/_partials' and 'go/foo/_partials' for template 'go/foo'. A directory is
+parsed once, at its first position. A partial overrides partials of the same
+name parsed before it, so a template can override partials it inherits.
+Partials directories are not template files and are ignored by lint's orphan
+check.
+
+
+Repository search path
+
+Boil searches for templates in an ordered list of repositories. The repository 
+given with the 'repository' option is searched first, then the default 
+repository and then repositories defined in the 'repositories' list of the 
+configuration file, in order:
+
+  "repositories": [
+    { "name": "team", "path": "/srv/templates" },
+    { "name": "vendor", "path": "git+https://example.com/templates.git" }
+  ]
+
+The first repository that contains the template is used. The default repository
+is named 'default' and the repository given on command line is named 'override'.
+To use a template from a specific repository prefix the template path with the 
+repository name and a colon, i.e.: 'team:apps/app'. The list command shows 
+which repository each template comes from.
+
+
+Git repositories
+
+A repository can be loaded from a git repository by specifying its url with a 
+'git+' prefix or an scp-like ssh address to the 'repository' option:
+
+  boil -r git+https://example.com/org/templates.git list
+  boil -r git@example.com:org/templates.git@v1.2 exec apps/app
+
+A tag, branch or commit to check out may be specified by appending it to the 
+url after a '@', i.e. '@v1.2' or '@feature/x'. If none is specified the 
+default branch is checked out.
+
+The repository is cloned to the boil directory in the user cache directory on
+first use, separately for each ref, and fetched every time it is opened. If
+fetching fails, i.e. when offline, the cached checkout is used and a warning is
+printed. Git must be installed.
+
+
+Archive repositories
+
+A .zip, .tar.gz or .tgz archive of a repository can be used as a read-only 
+repository by specifying the archive file name to the 'repository' option:
+
+  boil -r templates-v3.zip exec apps/app
+
+If the archive contains nothing but a single directory in its root, that 
+directory is used as the repository root. Commands that modify templates do not
+work with archive repositories.
 `
 
 const metafileText = `Metafile
 
-TODO: Metafile help.
+A template is a directory that contains a metafile named 'boil.json',
+'boil.yaml' or 'boil.toml'. If more than one exists they are used in that
+order. All formats use the same field names and are saved back in the format
+they were loaded from. The metafile is an object with following fields:
+
+  $schema      Optional JSON Schema reference used by editors.
+  name         Template name.
+  description  Template description.
+  author       Author object with "name", "email", "homepage" and
+               "modulePrefix" fields.
+  version      Template version.
+  url          Template url.
+  extends      Path of a template in the same repository to extend.
+  remove       Inherited "files", "directories", "prompts", "actions",
+               "groups", "conflicts" and "requires" to remove, by name.
+  requires     Templates in the same repository to execute first, each a path
+               with an optional version constraint, i.e. "go/utils/stringer
+               >=1.2".
+  files        Template files, either path strings or objects with a "path"
+               and a "when" condition.
+  directories  Directories to create, defined like files.
+  conflicts    Rules with a "pattern" and a "policy" for existing output files.
+  prompts      Prompts with "variable", "description", "regexp", "optional",
+               "type", "default", "choices" and "when" fields.
+  actions      Actions to run at "preParse", "preExecute" and "postExecute"
+               stages.
+  groups       Groups of child templates with "name", "description",
+               "groups" and "templates" fields, see 'boil help repository'.
+
+A template that extends another template inherits its files, directories,
+prompts, actions, groups and conflict rules. Extended templates may extend
+other templates. The extending template may add entries, override inherited
+entries with the same name or remove them by name, i.e.:
+
+  {
+    "extends": "apps/app",
+    "files": ["cmd/$ProjectName/main.go"],
+    "remove": {"files": ["README.md"], "actions": ["gomodinit"]}
+  }
+
+Entries are named by file and directory paths, prompt variables, action and
+group "name" fields, conflict rule patterns and required template paths.
+Inherited files are read from the extended template directory. 'boil info'
+prints the merged metafile.
+
+Required templates are executed before the template that requires them,
+recursively. A template required by more than one template is executed once
+and a variable prompted for by more than one template is asked for once.
+Versions of required templates must be semantic versions that satisfy all
+constraints. A constraint is a list of comparisons separated by spaces or
+commas, each an operator followed by a version, i.e. ">=1.2, <2" or ">= 1.2 < 2":
+
+  =1.2.3  ==1.2.3  !=1.2.3  >1.2  >=1.2  <2  <=2.1
+  ^1.2.3  Same as ">=1.2.3 <2.0.0", "^0.2.3" is ">=0.2.3 <0.3.0".
+  ~1.2.3  Same as ">=1.2.3 <1.3.0", "~1" is ">=1.0.0 <2.0.0".
+
+A version without an operator must be equal. A pre-release version such as
+"1.3.0-rc1" satisfies a constraint only if one of its comparisons names a
+pre-release of the same version, i.e. ">=1.3.0-beta". The version of a template
+that extends another template may be inherited from it.
+
+The complete format is defined by a JSON Schema printed by:
+
+  boil schema metafile
+
+Unknown fields, i.e. a misspelled "regExp" instead of "regexp", are ignored
+unless metafiles are loaded in strict mode using the global '--strict' flag or
+the "strict" configuration field. The 'validate' and 'lint' commands always load
+metafiles in strict mode.
 `
 
 const bastText = `Bast
@@ -366,6 +595,61 @@ TODO: BAST object reference.
 TODO: BAST function reference.
 `
 
+const funcsText = `Template functions
+
+Boil provides a library of functions to template files, action definitions,
+prompt defaults and entry conditions in addition to the standard text/template
+functions and Bast functions. Bast functions take precedence if names clash.
+
+Case conversion:
+
+  camel S          fooBar           pascal S         FooBar
+  snake S          foo_bar          kebab S          foo-bar
+  screaming S      FOO_BAR          title S          Foo Bar
+  lower S          lower case       upper S          UPPER CASE
+
+Words are split at characters that are not letters or digits and at changes of
+case, so "HTTPServer", "http-server" and "httpServer" convert alike. A lower
+case "s" ending an upper case run stays in its word, so "URLs" is one word.
+
+Inflection of the last word of S, preserving case:
+
+  plural S         user -> users, Person -> People, category -> categories,
+                   ID -> IDs
+  singular S       users -> user, People -> Person, categories -> category
+
+Go identifiers:
+
+  goIdent S        a valid Go identifier, "my-app" -> "myApp", "type" -> "type_"
+  exported S       an exported identifier, "user id" -> "UserId"
+  unexported S     an unexported identifier, "HTTPServer" -> "httpServer"
+
+Other functions:
+
+  now              the current time
+  date LAYOUT T    time T formatted using a Go time LAYOUT
+  uuid             a random version 4 UUID
+  indent N S       S with each line indented by N spaces
+  nindent N S      like indent, preceded by a new line
+  default DEF V    DEF if V is empty, otherwise V
+  coalesce V...    the first V that is not empty
+  join SEP LIST    elements of LIST joined with SEP
+  split SEP S      S split into a list at each SEP
+  toJson V         V encoded as JSON
+  toYaml V         V encoded as YAML
+
+Examples:
+
+  package {{.Vars.ProjectName | snake}}
+  // Generated on {{now | date "2006-01-02"}}.
+  type {{.Vars.Model | exported}}Store struct{}
+  func List{{.Vars.Model | plural | pascal}}() {}
+  {{default "MIT" .Vars.License}}
+
+Case conversion, inflection and Go identifier functions are also filters in
+file and directory path placeholders, see 'boil help exec'.
+`
+
 const globalsText = `
 About --no-repository
 
@@ -409,6 +693,85 @@ directory is opened in the system default file explorer.
 Edit subcommands open command prompt editors for metadata or parts of it.
 `
 
+const validateText = `
+Usage: boil validate <template-path>
+
+The validate command checks a template metafile and prints each problem found
+in the format "<metafile>: <field>: <problem>", for example:
+
+  apps/cliapp/boil.json: prompts[1].regexp: invalid regular expression: ...
+
+It checks that:
+
+ * every files and directories entry exists in the template directory,
+ * placeholders in entry paths refer to declared prompts or standard variables,
+ * entry paths use placeholders and not template actions such as
+   '{{.Vars.Name}}', which are not expanded in paths,
+ * prompt variables are unique and prompt types, regexps, choices, defaults and
+   conditions are valid,
+ * conflict rules define valid patterns and policies,
+ * every group template is a child template of the validated template.
+
+The command fails if any problems were found. Templates are also validated
+before they are executed by the exec command, except that missing directories
+are created and placeholders are checked against the actual variables.
+`
+
+const lintText = `
+Usage: boil lint [options]
+
+The lint command checks all templates in a repository and prints the problems
+found, one per line, in the format:
+
+  <severity>: <repository>:<file>[: <field>]: <message> (<check>)
+
+With '--json' the problems are printed as a JSON array of objects with
+"severity", "check", "repository", "template", "file", "field" and "message"
+fields.
+
+Errors:
+
+  validate        A metafile is not valid, see 'boil help validate'.
+  orphan-file     A file in a template directory is not listed in any metafile.
+                  Metafiles and '.gitkeep' files are ignored.
+  duplicate-name  Two or more templates have the same name.
+  group-cycle     A group directly or indirectly includes itself.
+  require-cycle   A template directly or indirectly requires itself.
+  template-parse  A template file or partial is not a valid text/template file.
+
+Warnings:
+
+  action-program  An action program is not found in PATH.
+  unused-prompt   A prompt variable is not used by the template or any of its
+                  child templates.
+
+By default all repositories in the search path are linted. If a repository is
+given with the global '--repository' flag only that repository is linted and
+'--name' lints only the named repository in the search path.
+
+The command exits with a non-zero status if any errors were found, warnings
+alone do not fail it. This allows it to be used from a pre-commit hook:
+
+  boil --repository ./templates lint || exit 1
+`
+
+const schemaText = `
+Usage: boil schema <metafile|config>
+
+The schema command prints the JSON Schema of a template metafile or of the boil
+configuration file. Schemas are generated from the definitions boil uses to
+load the files so they are always up to date with the running version.
+
+Schemas for the current version are also shipped in the 'schema' directory of
+the boil source repository. Reference a schema from a metafile using the
+"$schema" field to get completion and validation in editors that support it:
+
+  {
+    "$schema": "../../schema/metafile.schema.json",
+    "name": "app"
+  }
+`
+
 const editText = `
 Usage: boil edit <template-path> [options] [subcommand [options]]
 
@@ -430,6 +793,33 @@ placeholders in source template file names in the process and passes data
 defined on the command line or some other input to each template file as it is 
 executed to its output location.
 
+A placeholder in a file or directory path is a '$' followed by a variable name
+and optional filters, each a '|' followed by a filter name. Braces separate a
+placeholder from text that follows it and '$$' is a literal '$':
+
+  cmd/$ProjectName/main.go          cmd/MyApp/main.go
+  cmd/$ProjectName|snake/main.go    cmd/my_app/main.go
+  ${ProjectName|kebab}_test.go      my-app_test.go
+
+Template actions such as '{{.Vars.ProjectName}}' are not expanded in paths and
+are reported by validation.
+
+Filters are applied in order:
+
+  lower      lower case       upper      UPPER CASE
+  title      Title Case       snake      snake_case
+  screaming  SCREAMING_CASE   kebab      kebab-case
+  camel      camelCase        pascal     PascalCase
+  plural     plural form      singular   singular form
+  goIdent    Go identifier    exported   ExportedIdent
+  unexported unexportedIdent
+
+Filters are also available as template functions, see 'boil help funcs'.
+
+Execution fails if a placeholder refers to an undefined variable or filter, if
+a variable value contains characters that are not valid in file names on all
+platforms or if a path expands outside of the output directory.
+
 Exec command executes each entry in the order as defined for each set of actions
 defined in the template metafile at following stages of exec command:
 
@@ -453,6 +843,25 @@ If '--no-prompts' is specified in command line arguments no prompts defined in
 the template will be presented to the user but if a variable defined by a prompt
 is not oterwise given using the 'var' exec option the exec command will fail.
 
+The 'answers' option loads prompt answers from a JSON or a YAML file that maps 
+variable names to values, i.e.:
+
+  ProjectName: myapp
+  UseDocker: true
+  Features: [logging, config]
+
+Prompts answered by the file are not presented. Values given using the 'var' 
+option take precedence over the file.
+
+The 'non-interactive' option never prompts. Prompts that were not answered get
+their default values and execution fails if a required prompt has no value.
+Use it with 'answers' to execute templates reliably in scripts and CI.
+
+The 'record' option saves the value of every prompt of the execution, in order 
+and with the path of the template that defined it, to a JSON session file. The 
+'replay' option answers prompts from a session file so an execution can be 
+reproduced exactly, i.e. by a teammate or when reporting a template bug.
+
 Variables defined using the 'var' option can (currently) also override values 
 of exec option values, and take precedence over values given in options 
 themselves. Var option can be specified multiple times. 
@@ -473,4 +882,83 @@ declared up fron using the 'var' option or the execution will fail.
 
 Variable placeholders still work and the source template is copied recursively
 to the output directory.
+
+By default execution fails if any output file already exists in the output 
+directory. The 'on-conflict' option sets a policy for existing files:
+
+  fail       Fail the execution before anything is written (default).
+  skip       Keep the existing file.
+  overwrite  Overwrite the existing file.
+  append     Append output to the existing file.
+  prompt     Ask what to do for each existing file.
+  boilnew    Keep the existing file and write output to a '.boilnew' file.
+  merge      Merge output into the existing file. Regions changed on both 
+             sides are marked with conflict markers.
+
+A template can define policies per file pattern in its metafile 'conflicts' 
+list which take precedence over 'on-conflict'. The 'overwrite' option 
+overwrites all existing files regardless of policies.
+
+The 'no-execute' option executes all template files into memory over the output
+directory without writing anything. Actions are printed instead of being run
+and a report of files that would be written is printed, with each file marked
+as new, modified or unchanged.
+
+The 'diff' option works like 'no-execute' but prints a unified diff of each 
+output file against the file that already exists in the output directory. 
+Unless 'on-conflict' is given existing files are diffed as if overwritten so 
+the effect of a template on an existing project can be reviewed before 
+executing it with '--overwrite'.
+
+The 'manifest' option writes a manifest to '.boil/manifest.json' in the output
+directory. It records the template path and version, the variables the template
+was executed with and a hash of each generated file. Copies of generated files
+are stored in '.boil/base'. See 'upgrade' help topic.
+`
+
+const backupText = `
+Usage: boil backup [list | restore <id> | prune [options]]
+
+Unless backups are disabled in the configuration file the exec command backs up
+every file in the output directory that a template is about to create or 
+overwrite before executing a template. If template execution fails the backup 
+is restored automatically: overwritten files get their previous content back and
+files and empty directories that were created by the failed run are removed.
+
+Backups are stored in the boil directory of the user data directory, each under
+its own id. Backups are not removed after they are restored.
+
+ list:    Lists backups with their ids, creation times, number of files and the
+          output directory they were made for. This is the default subcommand.
+
+ restore: Restores a backup by id, undoing the run that created it.
+
+ prune:   Removes backups. The 'keep' option keeps at most the specified number
+          of newest backups and the 'older-than' option removes backups older
+          than a duration like '24h'. At least one of them must be specified.
+`
+
+const upgradeText = `
+Usage: boil upgrade [options]
+
+The upgrade command upgrades output of a template that was executed with the 
+'--manifest' option to the current version of the template.
+
+It reads the manifest from the output directory and executes the template it
+records again using the variables recorded in the manifest. Prompts are 
+presented only for variables that were added to the template since. Variables 
+given with the 'var' option override recorded values.
+
+Generated files that were not edited since are replaced with new output. Files 
+that were edited are merged with new output using a three-way merge with the 
+copies of files stored with the manifest as the common base. Regions changed 
+both by the user and the template are marked with conflict markers. The 
+'on-conflict' option sets a different policy, see 'exec' help topic.
+
+The manifest is updated after the upgrade.
+
+If the template version is not newer than the version recorded in the manifest 
+the output is reported as already up to date and the template is not executed.
+The 'force' option upgrades the output regardless of versions. Versions are 
+compared only if both the template and the manifest declare one.
 `
@@ -11,12 +11,17 @@ import (
 	"os"
 
 	"github.com/vedranvuk/boil/pkg/boil"
+	"github.com/vedranvuk/boil/pkg/commands/backup"
 	"github.com/vedranvuk/boil/pkg/commands/edit"
 	"github.com/vedranvuk/boil/pkg/commands/exec"
 	"github.com/vedranvuk/boil/pkg/commands/info"
+	"github.com/vedranvuk/boil/pkg/commands/lint"
 	"github.com/vedranvuk/boil/pkg/commands/list"
 	"github.com/vedranvuk/boil/pkg/commands/newt"
+	"github.com/vedranvuk/boil/pkg/commands/schema"
 	"github.com/vedranvuk/boil/pkg/commands/snap"
+	"github.com/vedranvuk/boil/pkg/commands/upgrade"
+	"github.com/vedranvuk/boil/pkg/commands/validate"
 	"github.com/vedranvuk/cmdline"
 )
 
@@ -73,9 +78,14 @@ func main() {
 			&cmdline.Optional{
 				LongName:    "repository",
 				ShortName:   "r",
-				Help:        "Override directory of repository to use.",
+				Help:        "Directory or git url of a repository to search first.",
 				MappedValue: &programConfig.Overrides.RepositoryPath,
 			},
+			&cmdline.Boolean{
+				LongName:    "strict",
+				Help:        "Reject metafiles with unknown fields.",
+				MappedValue: &programConfig.Overrides.Strict,
+			},
 		},
 		GlobalExclusivityGroups: []cmdline.ExclusivityGroup{
 			{
@@ -215,6 +225,61 @@ func main() {
 					})
 				},
 			},
+			{
+				Name: "validate",
+				Help: "Validate a template metafile.",
+				Options: cmdline.Options{
+					&cmdline.Indexed{
+						Name: "template-path",
+						Help: "Path of the template to validate.",
+					},
+				},
+				Handler: func(c cmdline.Context) error {
+					return validate.Run(&validate.Config{
+						TemplatePath: c.RawValues("template-path").First(),
+						Config:       programConfig,
+					})
+				},
+			},
+			{
+				Name: "lint",
+				Help: "Check all templates in a repository for problems.",
+				Options: cmdline.Options{
+					&cmdline.Optional{
+						LongName:  "name",
+						ShortName: "n",
+						Help:      "Name of the repository in the search path to lint.",
+					},
+					&cmdline.Boolean{
+						LongName:  "json",
+						ShortName: "j",
+						Help:      "Print issues as JSON.",
+					},
+				},
+				Handler: func(c cmdline.Context) error {
+					return lint.Run(&lint.Config{
+						Repository: c.RawValues("name").First(),
+						JSON:       c.IsParsed("json"),
+						Config:     programConfig,
+					})
+				},
+			},
+			{
+				Name: "schema",
+				Help: "Print the JSON Schema of a metafile or the configuration file.",
+				Options: cmdline.Options{
+					&cmdline.Indexed{
+						Name: "document",
+						Help: "Document to print the schema of, 'metafile' or 'config'.",
+					},
+				},
+				Handler: func(c cmdline.Context) error {
+					return schema.Run(&schema.Config{
+						Document: c.RawValues("document").First(),
+						Config:   programConfig,
+					})
+				},
+			},
 			{
 				Name: "edit",
 				Help: "Edit template metadata.",
@@ -357,18 +422,53 @@ func main() {
 					&cmdline.Boolean{
 						LongName:  "no-execute",
 						ShortName: "x",
-						Help:      "Print commands but do not execute them.",
+						Help:      "Execute into memory and print a report instead of writing output.",
+					},
+					&cmdline.Boolean{
+						LongName:  "manifest",
+						ShortName: "f",
+						Help:      "Write a manifest to the output directory to allow upgrades.",
+					},
+					&cmdline.Boolean{
+						LongName:  "diff",
+						ShortName: "d",
+						Help:      "Execute into memory and print a diff against the output directory.",
 					},
 					&cmdline.Boolean{
 						LongName:  "no-prompts",
 						ShortName: "p",
 						Help:      "Don't present input prompts for missing variables.",
 					},
+					&cmdline.Boolean{
+						LongName:  "non-interactive",
+						ShortName: "n",
+						Help:      "Never prompt, fail if a required prompt has no value.",
+					},
+					&cmdline.Optional{
+						LongName:  "answers",
+						ShortName: "a",
+						Help:      "JSON or YAML file with prompt answers.",
+					},
+					&cmdline.Optional{
+						LongName:  "record",
+						ShortName: "s",
+						Help:      "Save prompt answers to a session file.",
+					},
+					&cmdline.Optional{
+						LongName:  "replay",
+						ShortName: "y",
+						Help:      "Answer prompts from a session file.",
+					},
 					&cmdline.Boolean{
 						LongName:  "no-metadata",
 						ShortName: "m",
 						Help:      "No metadata mode. Copy template-path dir recursively.",
 					},
+					&cmdline.Optional{
+						LongName:  "on-conflict",
+						ShortName: "c",
+						Help:      "Existing file policy: fail, skip, overwrite, append, prompt, boilnew or merge.",
+					},
 					&cmdline.Optional{
 						LongName:  "output-dir",
 						ShortName: "o",
@@ -398,19 +498,135 @@ func main() {
 					}
 					// Execute Exec Command.
 					return exec.Run(&exec.Config{
-						TemplatePath:  c.RawValues("template-path").First(),
-						OutputDir:     c.RawValues("output-dir").First(),
-						Overwrite:     c.IsParsed("overwrite"),
-						NoPrompts:     c.IsParsed("no-prompts"),
-						NoMetadata:    c.IsParsed("no-metadata"),
-						EditAfterExec: c.IsParsed("edit"),
-						GoInputs:      c.RawValues("go-input"),
-						JsonInputs:    c.RawValues("json-input"),
-						Vars:          vars,
-						Config:        programConfig,
+						TemplatePath:   c.RawValues("template-path").First(),
+						OutputDir:      c.RawValues("output-dir").First(),
+						Overwrite:      c.IsParsed("overwrite"),
+						OnConflict:     c.RawValues("on-conflict").First(),
+						NoExecute:      c.IsParsed("no-execute"),
+						Diff:           c.IsParsed("diff"),
+						Manifest:       c.IsParsed("manifest"),
+						NoPrompts:      c.IsParsed("no-prompts"),
+						NonInteractive: c.IsParsed("non-interactive"),
+						Answers:        c.RawValues("answers").First(),
+						Record:         c.RawValues("record").First(),
+						Replay:         c.RawValues("replay").First(),
+						NoMetadata:     c.IsParsed("no-metadata"),
+						EditAfterExec:  c.IsParsed("edit"),
+						GoInputs:       c.RawValues("go-input"),
+						JsonInputs:     c.RawValues("json-input"),
+						Vars:           vars,
+						Config:         programConfig,
 					})
 				},
 			},
+			{
+				Name: "upgrade",
+				Help: "Upgrade output of a template execution to a newer template version.",
+				Options: cmdline.Options{
+					&cmdline.Optional{
+						LongName:  "output-dir",
+						ShortName: "o",
+						Help:      "Output directory with a manifest (default: current directory).",
+					},
+					&cmdline.Optional{
+						LongName:  "template-path",
+						ShortName: "t",
+						Help:      "Override the template path recorded in the manifest.",
+					},
+					&cmdline.Optional{
+						LongName:  "on-conflict",
+						ShortName: "c",
+						Help:      "Existing file policy (default: merge).",
+					},
+					&cmdline.Boolean{
+						LongName:  "no-prompts",
+						ShortName: "p",
+						Help:      "Don't present prompts for new variables or conflicts.",
+					},
+					&cmdline.Boolean{
+						LongName:  "no-execute",
+						ShortName: "x",
+						Help:      "Execute into memory and print a report instead of writing output.",
+					},
+					&cmdline.Boolean{
+						LongName:  "diff",
+						ShortName: "d",
+						Help:      "Execute into memory and print a diff against the output directory.",
+					},
+					&cmdline.Boolean{
+						LongName:  "force",
+						ShortName: "f",
+						Help:      "Upgrade even if the template version is not newer.",
+					},
+					&cmdline.Repeated{
+						LongName:  "var",
+						ShortName: "r",
+						Help:      "Set or override a variable value.",
+					},
+				},
+				Handler: func(c cmdline.Context) (err error) {
+					var vars = make(boil.Variables)
+					if err = vars.SetAssignments(c.RawValues("var")...); err != nil {
+						return
+					}
+					return upgrade.Run(&upgrade.Config{
+						OutputDir:    c.RawValues("output-dir").First(),
+						TemplatePath: c.RawValues("template-path").First(),
+						OnConflict:   c.RawValues("on-conflict").First(),
+						NoPrompts:    c.IsParsed("no-prompts"),
+						NoExecute:    c.IsParsed("no-execute"),
+						Diff:         c.IsParsed("diff"),
+						Force:        c.IsParsed("force"),
+						Vars:         vars,
+						Config:       programConfig,
+					})
+				},
+			},
+			{
+				Name: "backup",
+				Help: "List, restore or prune output directory backups.",
+				Handler: func(c cmdline.Context) error {
+					return backup.Run(&backup.Config{
+						Action: "list",
+						Config: programConfig,
+					})
+				},
+				SubCommands: cmdline.Commands{
+					{
+						Name:    "list",
+						Help:    "List backups.",
+						Handler: handleBackupSubCommand,
+					},
+					{
+						Name: "restore",
+						Help: "Restore a backup.",
+						Options: cmdline.Options{
+							&cmdline.Indexed{
+								Name: "id",
+								Help: "ID of the backup to restore.",
+							},
+						},
+						Handler: handleBackupSubCommand,
+					},
+					{
+						Name: "prune",
+						Help: "Remove old backups.",
+						Options: cmdline.Options{
+							&cmdline.Optional{
+								LongName:  "keep",
+								ShortName: "k",
+								Help:      "Number of newest backups to keep.",
+							},
+							&cmdline.Optional{
+								LongName:  "older-than",
+								ShortName: "o",
+								Help:      "Remove backups older than a duration, i.e. '72h'.",
+							},
+						},
+						Handler: handleBackupSubCommand,
+					},
+				},
+			},
 		},
 	}
 	// Parse command line.
@@ -458,3 +674,14 @@ func handleEditSubCommand(c cmdline.Context) error {
 
 	return edit.Run(config)
 }
+
+// handleBackupSubCommand handles the backup command subcommands.
+func handleBackupSubCommand(c cmdline.Context) error {
+	return backup.Run(&backup.Config{
+		Action:    c.GetCommand().Name,
+		ID:        c.RawValues("id").First(),
+		Keep:      c.RawValues("keep").First(),
+		OlderThan: c.RawValues("older-than").First(),
+		Config:    programConfig,
+	})
+}
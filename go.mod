@@ -3,10 +3,12 @@ module github.com/vedranvuk/boil
 go 1.21.0
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/adrg/xdg v0.4.0
 	github.com/vedranvuk/bast v0.0.0-00010101000000-000000000000
 	github.com/vedranvuk/cmdline v0.0.0-20230731121628-0e879a0d21b4
 	github.com/vedranvuk/tmpl v0.0.0-00010101000000-000000000000
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -20,6 +20,10 @@ func NewAction() *Action {
 // Action defines some external action to execute via command line.
 // See Metafile.Actions for details on Action usage.
 type Action struct {
+	// Name is an optional Action name. If set, it must be unique in an Action
+	// group. Templates that extend a Template use it to override or remove
+	// inherited Actions. See Metafile.Extends.
+	Name string `json:"name,omitempty"`
 	// Description is the description text of the Action. It's an optional text
 	// that should describe the action purpose.
 	Description string `json:"description,omitempty"`
@@ -90,3 +94,34 @@ func (self Actions) ExecuteAll(data *Data) (err error) {
 	}
 	return
 }
+
+// RunAll runs all actions in self using runner. Returns the error of the
+// first action that returns it and stops further execution or nil if no
+// errors occur.
+func (self Actions) RunAll(runner ActionRunner, data *Data) (err error) {
+	for _, action := range self {
+		if err = runner.Run(action, data); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ActionRunner runs Actions during Template execution.
+type ActionRunner interface {
+	// Run runs action using data to expand its definition and returns nil on
+	// success or an error.
+	Run(action *Action, data *Data) error
+}
+
+// ActionRunnerFunc is a function that implements ActionRunner.
+type ActionRunnerFunc func(action *Action, data *Data) error
+
+// Run implements ActionRunner.Run by calling self.
+func (self ActionRunnerFunc) Run(action *Action, data *Data) error { return self(action, data) }
+
+// DefaultActionRunner is the ActionRunner that runs actions by calling
+// Action.Execute.
+var DefaultActionRunner ActionRunner = ActionRunnerFunc(func(action *Action, data *Data) error {
+	return action.Execute(data)
+})
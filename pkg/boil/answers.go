@@ -0,0 +1,40 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadAnswers loads prompt answers from a JSON or a YAML file and returns
+// them as Variables or an error.
+//
+// The file must contain an object that maps variable names to values. The
+// format is determined by the file extension, ".yaml" and ".yml" files are
+// read as YAML and all other files as JSON. Values are converted to prompt
+// types during execution, see Prompt.ConvertValue.
+func LoadAnswers(filename string) (answers Variables, err error) {
+	var data []byte
+	if data, err = os.ReadFile(filename); err != nil {
+		return nil, fmt.Errorf("read answers file: %w", err)
+	}
+	answers = make(Variables)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &answers)
+	default:
+		err = json.Unmarshal(data, &answers)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal answers file: %w", err)
+	}
+	return
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// typedRepository is a repository with a "svc" template that has prompts of
+// types that JSON and YAML decode differently, i.e. numbers and lists.
+var typedRepository = fstest.MapFS{
+	"svc/boil.json": {Data: []byte(`{
+		"name": "svc",
+		"version": "1.0.0",
+		"files": [
+			{"path": "main.go"}
+		],
+		"prompts": [
+			{"variable": "Name"},
+			{"variable": "Port", "type": "int", "default": "8080"},
+			{"variable": "Debug", "type": "bool", "default": "no"},
+			{"variable": "Tags", "type": "list", "optional": true},
+			{"variable": "Features", "type": "multi-choice", "choices": ["http", "grpc", "cli"], "default": "http"}
+		]
+	}`)},
+	"svc/main.go": {Data: []byte(`package {{.Vars.Name | snake}}` +
+		` // port {{if eq .Vars.Port 9090}}custom{{else}}default{{end}}` +
+		` debug {{.Vars.Debug}}` +
+		` tags{{range .Vars.Tags}} {{.}}{{end}}` +
+		` features{{range .Vars.Features}} {{.}}{{end}}`)},
+}
+
+// typedOutput is the "svc" output for typedVars.
+const typedOutput = "package my_svc // port custom debug true tags a b features grpc cli"
+
+// typedVars are the values of typedRepository prompts with prompt types.
+var typedVars = Variables{
+	"Name":     "MySvc",
+	"Port":     9090,
+	"Debug":    true,
+	"Tags":     []string{"a", "b"},
+	"Features": []string{"grpc", "cli"},
+}
+
+func TestExecutorAnswers(t *testing.T) {
+
+	var (
+		dir = t.TempDir()
+		out = filepath.Join(string(filepath.Separator), "out")
+	)
+	for _, test := range []struct {
+		name string
+		data string
+	}{
+		{"answers.json", `{
+			"Name": "MySvc",
+			"Port": 9090,
+			"Debug": true,
+			"Tags": ["a", "b"],
+			"Features": ["grpc", "cli"]
+		}`},
+		{"answers.yaml", "Name: MySvc\nPort: 9090\nDebug: yes\nTags: [a, b]\nFeatures:\n  - grpc\n  - cli\n"},
+		{"answers.yml", "Name: MySvc\nPort: \"9090\"\nDebug: \"true\"\nTags: a, b\nFeatures: grpc,cli\n"},
+	} {
+		var filename = filepath.Join(dir, test.name)
+		if err := os.WriteFile(filename, []byte(test.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var answers, err = LoadAnswers(filename)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		var args []string
+		var exec, output = newTestExecutor(typedRepository, nil, &args)
+		exec.Prompter = nil
+		exec.NonInteractive = true
+		exec.Vars = answers
+		if err = exec.Execute("svc", out); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var data []byte
+		if data, err = output.ReadFile(filepath.Join(out, "main.go")); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if string(data) != typedOutput {
+			t.Errorf("%s: got %q, want %q", test.name, data, typedOutput)
+		}
+		for name, want := range typedVars {
+			if got := exec.Data.Vars[name]; !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %s: got %#v, want %#v", test.name, name, got, want)
+			}
+		}
+	}
+
+	// Defaults are used for variables missing from answers, a required
+	// variable without a default is an error.
+	var args []string
+	var exec, output = newTestExecutor(typedRepository, nil, &args)
+	exec.Prompter = nil
+	exec.NonInteractive = true
+	exec.Vars = Variables{"Name": "MySvc"}
+	if err := exec.Execute("svc", out); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := output.ReadFile(filepath.Join(out, "main.go")); err != nil ||
+		string(data) != "package my_svc // port default debug false tags features http" {
+		t.Errorf("defaults: got %q, %v", data, err)
+	}
+	exec.Vars = Variables{"Port": 9090.0}
+	exec.Overwrite = true
+	if err := exec.Execute("svc", out); err == nil {
+		t.Error("expected error for missing required variable")
+	}
+
+	// Answers of a wrong type are errors.
+	exec.Vars = Variables{"Name": "MySvc", "Port": 1.5}
+	if err := exec.Execute("svc", out); err == nil {
+		t.Error("expected error for non integer answer")
+	}
+}
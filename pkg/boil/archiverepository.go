@@ -0,0 +1,222 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IsArchivePath returns true if path has an extension of an archive format
+// supported by ArchiveRepository: ".zip", ".tar.gz" or ".tgz".
+func IsArchivePath(path string) bool {
+	var lower = strings.ToLower(path)
+	return strings.HasSuffix(lower, ".zip") ||
+		strings.HasSuffix(lower, ".tar.gz") ||
+		strings.HasSuffix(lower, ".tgz")
+}
+
+// ArchiveRepository is a read-only Repository backed by a .zip or a .tar.gz
+// archive file. The archive is loaded into memory when opened and served by
+// the embedded FSRepository.
+//
+// If the archive root contains nothing but a single directory that directory
+// is used as the repository root, so archives of a repository directory and
+// of its contents are both supported.
+type ArchiveRepository struct {
+	*FSRepository
+	// filename is the archive file name.
+	filename string
+}
+
+// OpenArchiveRepository opens an archive file as a repository and returns it
+// or an error. The archive format is determined by the filename extension.
+func OpenArchiveRepository(filename string) (repo *ArchiveRepository, err error) {
+
+	var data []byte
+	if data, err = os.ReadFile(filename); err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+
+	var fsys fs.FS
+	switch lower := strings.ToLower(filename); {
+	case strings.HasSuffix(lower, ".zip"):
+		if fsys, err = zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+			return nil, fmt.Errorf("open zip archive: %w", err)
+		}
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		if fsys, err = newTarFS(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("open tar archive: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", filename)
+	}
+
+	// Descend into a single root directory.
+	var entries []fs.DirEntry
+	if entries, err = fs.ReadDir(fsys, "."); err != nil {
+		return nil, fmt.Errorf("read archive root: %w", err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		if fsys, err = fs.Sub(fsys, entries[0].Name()); err != nil {
+			return nil, fmt.Errorf("open archive root: %w", err)
+		}
+	}
+
+	return &ArchiveRepository{NewFSRepository(fsys), filename}, nil
+}
+
+// Location implements Repository.Location.
+// It returns the archive file name.
+func (self *ArchiveRepository) Location() string { return self.filename }
+
+// tarFS is a read-only in-memory fs.FS loaded from a gzipped tar archive.
+type tarFS map[string]*tarEntry
+
+// tarEntry is a file or a directory in a tarFS.
+type tarEntry struct {
+	name     string
+	data     []byte
+	mode     fs.FileMode
+	modTime  time.Time
+	children []fs.DirEntry
+}
+
+// newTarFS loads a tarFS from gzipped tar archive data read from r.
+func newTarFS(r io.Reader) (tfs tarFS, err error) {
+
+	var gz *gzip.Reader
+	if gz, err = gzip.NewReader(r); err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tfs = tarFS{".": {name: ".", mode: fs.ModeDir | 0755}}
+
+	var (
+		tr  = tar.NewReader(gz)
+		hdr *tar.Header
+	)
+	for {
+		if hdr, err = tr.Next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		var name = path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if !fs.ValidPath(name) || name == "." {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			tfs.mkdirAll(name, hdr.ModTime)
+		case tar.TypeReg:
+			var entry = &tarEntry{
+				name:    path.Base(name),
+				mode:    fs.FileMode(hdr.Mode).Perm(),
+				modTime: hdr.ModTime,
+			}
+			if entry.data, err = io.ReadAll(tr); err != nil {
+				return nil, err
+			}
+			tfs.mkdirAll(path.Dir(name), hdr.ModTime)
+			tfs.add(name, entry)
+		}
+	}
+
+	for _, entry := range tfs {
+		sort.Slice(entry.children, func(i, j int) bool {
+			return entry.children[i].Name() < entry.children[j].Name()
+		})
+	}
+
+	return tfs, nil
+}
+
+// mkdirAll adds directory entries along name that do not exist.
+func (self tarFS) mkdirAll(name string, modTime time.Time) {
+	if _, exists := self[name]; exists || name == "." {
+		return
+	}
+	self.mkdirAll(path.Dir(name), modTime)
+	self.add(name, &tarEntry{
+		name:    path.Base(name),
+		mode:    fs.ModeDir | 0755,
+		modTime: modTime,
+	})
+}
+
+// add adds entry under name and registers it with its parent directory.
+func (self tarFS) add(name string, entry *tarEntry) {
+	if _, exists := self[name]; !exists {
+		var parent = self[path.Dir(name)]
+		parent.children = append(parent.children, fs.FileInfoToDirEntry(entry))
+	}
+	self[name] = entry
+}
+
+// Open implements fs.FS.
+func (self tarFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	var entry, exists = self[name]
+	if !exists {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &tarFile{tarEntry: entry, Reader: bytes.NewReader(entry.data)}, nil
+}
+
+// tarFile is an open tarEntry.
+type tarFile struct {
+	*tarEntry
+	*bytes.Reader
+	// offset is the ReadDir offset.
+	offset int
+}
+
+func (self *tarFile) Stat() (fs.FileInfo, error) { return self.tarEntry, nil }
+
+func (self *tarFile) Close() error { return nil }
+
+// ReadDir implements fs.ReadDirFile.
+func (self *tarFile) ReadDir(n int) (entries []fs.DirEntry, err error) {
+	if !self.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: self.name, Err: errors.New("not a directory")}
+	}
+	var rest = self.children[self.offset:]
+	if n > 0 && len(rest) > n {
+		rest = rest[:n]
+	}
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	self.offset += len(rest)
+	return append(entries, rest...), nil
+}
+
+func (self *tarEntry) Name() string { return self.name }
+
+func (self *tarEntry) Size() int64 { return int64(len(self.data)) }
+
+func (self *tarEntry) Mode() fs.FileMode { return self.mode }
+
+func (self *tarEntry) ModTime() time.Time { return self.modTime }
+
+func (self *tarEntry) IsDir() bool { return self.mode.IsDir() }
+
+func (self *tarEntry) Sys() any { return nil }
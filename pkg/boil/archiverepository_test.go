@@ -0,0 +1,185 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// testArchiveFiles are files of test archives by archive path.
+var testArchiveFiles = map[string]string{
+	"repo/app/boil.json":          `{"name": "app", "files": [{"path": "main.go"}]}`,
+	"repo/app/main.go":            `package main`,
+	"repo/app/sub/deep/file.txt":  `deep`,
+	"./repo//app/../readme.md":    `readme`,
+	"../escape.txt":               `escape`,
+	"/absolute.txt":               `absolute`,
+	"repo/app/sub/deep/../up.txt": `up`,
+}
+
+// newTestTarGz returns a gzipped tar archive of testArchiveFiles with a
+// directory entry for "repo" and a symbolic link that is ignored.
+func newTestTarGz(t *testing.T) []byte {
+	var (
+		buf bytes.Buffer
+		gz  = gzip.NewWriter(&buf)
+		tw  = tar.NewWriter(gz)
+	)
+	if err := tw.WriteHeader(&tar.Header{Name: "repo/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "repo/link", Typeflag: tar.TypeSymlink, Linkname: "app"}); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range testArchiveFiles {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newTestZip returns a zip archive of valid testArchiveFiles.
+func newTestZip(t *testing.T) []byte {
+	var (
+		buf bytes.Buffer
+		zw  = zip.NewWriter(&buf)
+	)
+	for _, name := range []string{"repo/app/boil.json", "repo/app/main.go", "repo/app/sub/deep/file.txt"} {
+		var w, err = zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(testArchiveFiles[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTarFS(t *testing.T) {
+
+	var tfs, err = newTarFS(bytes.NewReader(newTestTarGz(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = fstest.TestFS(tfs,
+		"repo/app/boil.json",
+		"repo/app/main.go",
+		"repo/app/sub/deep/file.txt",
+		"repo/app/sub/up.txt",
+		"repo/readme.md",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// Paths are cleaned, escaping and absolute paths and links are skipped,
+	// so the archive root holds only the "repo" directory.
+	var names []string
+	if err = fs.WalkDir(tfs, ".", func(path string, d fs.DirEntry, err error) error {
+		names = append(names, path)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var want = []string{
+		".", "repo", "repo/app", "repo/app/boil.json", "repo/app/main.go",
+		"repo/app/sub", "repo/app/sub/deep", "repo/app/sub/deep/file.txt", "repo/app/sub/up.txt",
+		"repo/readme.md",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("walk: got %v, want %v", names, want)
+	}
+
+	var fi fs.FileInfo
+	if fi, err = fs.Stat(tfs, "repo/app/sub"); err != nil || !fi.IsDir() || fi.Name() != "sub" {
+		t.Errorf("stat implicit dir: got %v, %v", fi, err)
+	}
+	if fi, err = fs.Stat(tfs, "repo/app/main.go"); err != nil || fi.IsDir() || fi.Size() != int64(len("package main")) || fi.Mode() != 0644 {
+		t.Errorf("stat file: got %v, %v", fi, err)
+	}
+	for _, name := range []string{"missing", "../escape.txt", "/repo", "repo/app/"} {
+		if _, err = tfs.Open(name); err == nil {
+			t.Errorf("open %q: expected error", name)
+		}
+	}
+	var entries []fs.DirEntry
+	if entries, err = fs.ReadDir(tfs, "repo/app"); err != nil || len(entries) != 3 || entries[0].Name() != "boil.json" || !entries[2].IsDir() {
+		t.Errorf("readdir: got %v, %v", entries, err)
+	}
+	if _, err = fs.ReadDir(tfs, "repo/app/main.go"); err == nil {
+		t.Error("readdir of a file: expected error")
+	}
+}
+
+func TestOpenArchiveRepository(t *testing.T) {
+
+	var dir = t.TempDir()
+	for name, data := range map[string][]byte{
+		"repo.zip":    newTestZip(t),
+		"repo.tar.gz": newTestTarGz(t),
+		"repo.TGZ":    newTestTarGz(t),
+		"repo.rar":    nil,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"repo.zip", "repo.tar.gz", "repo.TGZ"} {
+		var repo, err = OpenArchiveRepository(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if repo.Location() != filepath.Join(dir, name) {
+			t.Errorf("%s: location: got %s", name, repo.Location())
+		}
+		// A single root directory is the repository root.
+		var meta *Metafile
+		if meta, err = repo.OpenMeta("app"); err != nil || meta.Name != "app" {
+			t.Fatalf("%s: open meta: %v", name, err)
+		}
+		var data []byte
+		if data, err = repo.ReadFile(filepath.Join("app", "sub", "deep", "file.txt")); err != nil || string(data) != "deep" {
+			t.Errorf("%s: read nested file: got %q, %v", name, data, err)
+		}
+	}
+
+	if _, err := OpenArchiveRepository(filepath.Join(dir, "repo.rar")); err == nil {
+		t.Error("unsupported format: expected error")
+	}
+	if _, err := OpenArchiveRepository(filepath.Join(dir, "missing.zip")); err == nil {
+		t.Error("missing archive: expected error")
+	}
+
+	for path, want := range map[string]bool{
+		"a.zip": true, "a.ZIP": true, "a.tar.gz": true, "a.tgz": true, "a.tar": false, "a": false,
+	} {
+		if IsArchivePath(path) != want {
+			t.Errorf("IsArchivePath(%q): got %t", path, !want)
+		}
+	}
+}
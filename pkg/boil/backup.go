@@ -4,12 +4,330 @@
 
 package boil
 
-// CreateBackup creates a backup of a directory using config to determine
-// backup location. Returns the backup id and nil on success or an empty string
-// and an error otherwise.
-func CreateBackup(dir string) (string, error) {
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
 
-	return "", nil
+const (
+	// BackupMetafileName is the name of the file inside a backup directory
+	// that describes the backup.
+	BackupMetafileName = "backup.json"
+	// backupFilesDir is the name of the directory inside a backup directory
+	// that holds copies of backed up files.
+	backupFilesDir = "files"
+)
+
+// ErrBackupNotFound is returned when a backup with a specified id does not
+// exist in the backup directory.
+var ErrBackupNotFound = errors.New("backup not found")
+
+// Backup describes a snapshot of files in an output directory taken before
+// Template execution.
+type Backup struct {
+	// ID is the unique backup identifier.
+	ID string `json:"id"`
+	// Created is the time the backup was created.
+	Created time.Time `json:"created"`
+	// Dir is the absolute path of the directory the backup was made for.
+	Dir string `json:"dir"`
+	// Files are the files that were backed up, existing or not.
+	Files []*BackupFile `json:"files,omitempty"`
+	// Dirs are absolute paths of directories that did not exist at backup
+	// time and are removed on restore if they are empty.
+	Dirs []string `json:"dirs,omitempty"`
+}
+
+// BackupFile describes a single file in a Backup.
+type BackupFile struct {
+	// Path is the absolute path of the file.
+	Path string `json:"path"`
+	// Existed is true if the file existed at backup time in which case its
+	// copy is stored with the backup. Otherwise the file is deleted on
+	// restore.
+	Existed bool `json:"existed"`
+	// Mode is the file mode of an existing file.
+	Mode fs.FileMode `json:"mode,omitempty"`
+}
+
+// CreateBackup creates a backup of files and dirs in directory dir in the
+// default backup directory. Files and dirs are absolute paths of files and
+// directories that are about to be created or overwritten inside dir.
+//
+// Files that exist are copied to the backup and files that do not exist are
+// recorded so that RestoreBackup removes them. Directories along the path of
+// any file or dir, including dir itself, that do not exist are recorded so
+// RestoreBackup removes them if they are empty.
+//
+// Returns the backup id and nil on success or an empty string and an error
+// otherwise.
+func CreateBackup(dir string, files, dirs []string) (id string, err error) {
+
+	var backup = &Backup{
+		Created: time.Now(),
+	}
+	if backup.Dir, err = filepath.Abs(dir); err != nil {
+		return "", fmt.Errorf("get absolute backup dir: %w", err)
+	}
+	if backup.ID, err = newBackupID(backup.Created); err != nil {
+		return "", fmt.Errorf("generate backup id: %w", err)
+	}
+
+	var (
+		root    = filepath.Join(DefaultBackupDir(), backup.ID)
+		missing = make(map[string]bool)
+		fi      fs.FileInfo
+	)
+	if err = os.MkdirAll(filepath.Join(root, backupFilesDir), os.ModePerm); err != nil {
+		return "", fmt.Errorf("create backup dir: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(root)
+		}
+	}()
+
+	// Record directories that do not exist.
+	for _, path := range append(append([]string{}, dirs...), files...) {
+		for dir := filepath.Dir(path); isSubPath(backup.Dir, dir); dir = filepath.Dir(dir) {
+			if err = backupRecordMissingDir(dir, missing); err != nil {
+				return
+			}
+			if dir == backup.Dir {
+				break
+			}
+		}
+	}
+	for _, dir := range append([]string{backup.Dir}, dirs...) {
+		if err = backupRecordMissingDir(dir, missing); err != nil {
+			return
+		}
+	}
+	for dir := range missing {
+		backup.Dirs = append(backup.Dirs, dir)
+	}
+	sort.Strings(backup.Dirs)
+
+	// Copy files that exist and record files that do not.
+	for _, file := range files {
+		var bf = &BackupFile{Path: file}
+		if fi, err = os.Stat(file); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("stat backup file: %w", err)
+			}
+			err = nil
+			backup.Files = append(backup.Files, bf)
+			continue
+		}
+		if fi.IsDir() {
+			return "", fmt.Errorf("backup file is a directory: %s", file)
+		}
+		bf.Existed = true
+		bf.Mode = fi.Mode().Perm()
+		if err = copyFile(file, backupFilePath(root, backup.Dir, file), bf.Mode); err != nil {
+			return "", fmt.Errorf("backup file: %w", err)
+		}
+		backup.Files = append(backup.Files, bf)
+	}
+
+	var data []byte
+	if data, err = json.MarshalIndent(backup, "", "\t"); err != nil {
+		return "", fmt.Errorf("marshal backup: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(root, BackupMetafileName), data, os.ModePerm); err != nil {
+		return "", fmt.Errorf("write backup metafile: %w", err)
+	}
+
+	return backup.ID, nil
+}
+
+// RestoreBackup restores a backup with the specified id.
+//
+// Files that existed at backup time are restored to their backed up content,
+// files that did not exist are deleted and directories that did not exist are
+// removed if they are empty. The backup itself is not removed.
+func RestoreBackup(id string) (err error) {
+
+	var backup *Backup
+	if backup, err = OpenBackup(id); err != nil {
+		return
+	}
+
+	var root = filepath.Join(DefaultBackupDir(), backup.ID)
+	for _, file := range backup.Files {
+		if !file.Existed {
+			if err = os.Remove(file.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("remove file: %w", err)
+			}
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(file.Path), os.ModePerm); err != nil {
+			return fmt.Errorf("create file dir: %w", err)
+		}
+		if err = copyFile(backupFilePath(root, backup.Dir, file.Path), file.Path, file.Mode); err != nil {
+			return fmt.Errorf("restore file: %w", err)
+		}
+	}
+
+	// Remove created directories deepest first, leave non-empty ones.
+	for i := len(backup.Dirs) - 1; i >= 0; i-- {
+		var entries []fs.DirEntry
+		if entries, err = os.ReadDir(backup.Dirs[i]); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("read created dir: %w", err)
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		if err = os.Remove(backup.Dirs[i]); err != nil {
+			return fmt.Errorf("remove created dir: %w", err)
+		}
+	}
+
+	return nil
 }
 
-func RestoreBackup(id string) error { return nil }
+// OpenBackup loads the backup with the specified id from the default backup
+// directory. If the backup does not exist ErrBackupNotFound is returned.
+func OpenBackup(id string) (backup *Backup, err error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return nil, fmt.Errorf("invalid backup id: '%s'", id)
+	}
+	var data []byte
+	if data, err = os.ReadFile(filepath.Join(DefaultBackupDir(), id, BackupMetafileName)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrBackupNotFound, id)
+		}
+		return nil, fmt.Errorf("read backup metafile: %w", err)
+	}
+	backup = new(Backup)
+	if err = json.Unmarshal(data, backup); err != nil {
+		return nil, fmt.Errorf("unmarshal backup metafile: %w", err)
+	}
+	return
+}
+
+// ListBackups returns all backups in the default backup directory sorted by
+// creation time, oldest first. Directories that do not contain a valid backup
+// are skipped.
+func ListBackups() (backups []*Backup, err error) {
+	var entries []fs.DirEntry
+	if entries, err = os.ReadDir(DefaultBackupDir()); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read backup dir: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		var backup *Backup
+		if backup, err = OpenBackup(entry.Name()); err != nil {
+			err = nil
+			continue
+		}
+		backups = append(backups, backup)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Created.Before(backups[j].Created)
+	})
+	return
+}
+
+// RemoveBackup removes the backup with the specified id.
+func RemoveBackup(id string) (err error) {
+	if _, err = OpenBackup(id); err != nil {
+		return
+	}
+	if err = os.RemoveAll(filepath.Join(DefaultBackupDir(), id)); err != nil {
+		return fmt.Errorf("remove backup: %w", err)
+	}
+	return nil
+}
+
+// PruneBackups removes backups, oldest first, leaving at most keep newest
+// backups. If keep is less than zero the count is not limited. If before is
+// not zero backups created before that time are removed regardless of keep.
+//
+// Returns removed backups and nil or an error if one occured.
+func PruneBackups(keep int, before time.Time) (pruned []*Backup, err error) {
+	var backups []*Backup
+	if backups, err = ListBackups(); err != nil {
+		return
+	}
+	for i, backup := range backups {
+		if (keep < 0 || i >= len(backups)-keep) &&
+			(before.IsZero() || !backup.Created.Before(before)) {
+			continue
+		}
+		if err = RemoveBackup(backup.ID); err != nil {
+			return
+		}
+		pruned = append(pruned, backup)
+	}
+	return
+}
+
+// newBackupID returns a new sortable unique backup id based on t.
+func newBackupID(t time.Time) (string, error) {
+	var buf = make([]byte, 3)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return t.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(buf), nil
+}
+
+// backupFilePath returns the path of the backup copy of file stored in a backup
+// at root for a backup of dir.
+func backupFilePath(root, dir, file string) string {
+	var rel, err = filepath.Rel(dir, file)
+	if err != nil || !isSubPath(dir, file) {
+		rel = strings.TrimPrefix(filepath.Clean(file), filepath.VolumeName(file))
+	}
+	return filepath.Join(root, backupFilesDir, rel)
+}
+
+// backupRecordMissingDir adds dir to missing if it does not exist.
+func backupRecordMissingDir(dir string, missing map[string]bool) (err error) {
+	if _, err = os.Stat(dir); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("stat backup dir: %w", err)
+		}
+		missing[dir] = true
+	}
+	return nil
+}
+
+// isSubPath returns true if path equals root or is a path inside root.
+func isSubPath(root, path string) bool {
+	var rel, err = filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// copyFile copies file src to dst with mode creating dst directories as
+// needed.
+func copyFile(src, dst string, mode fs.FileMode) (err error) {
+	var data []byte
+	if data, err = os.ReadFile(src); err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return
+	}
+	if mode == 0 {
+		mode = os.ModePerm
+	}
+	return os.WriteFile(dst, data, mode)
+}
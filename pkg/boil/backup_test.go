@@ -0,0 +1,164 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// useTempDataHome makes the backup directory a temporary directory for the
+// duration of the test t.
+func useTempDataHome(t *testing.T) {
+	var data = xdg.DataHome
+	xdg.DataHome = t.TempDir()
+	t.Cleanup(func() { xdg.DataHome = data })
+}
+
+// checkFile fails t if file does not have content data or, if data is empty,
+// if file exists.
+func checkFile(t *testing.T, file, data string) {
+	t.Helper()
+	var content, err = os.ReadFile(file)
+	if data == "" {
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s: expected not to exist, got %q, %v", file, content, err)
+		}
+		return
+	}
+	if err != nil || string(content) != data {
+		t.Errorf("%s: got %q, %v, want %q", file, content, err, data)
+	}
+}
+
+func TestBackup(t *testing.T) {
+
+	useTempDataHome(t)
+
+	var (
+		dir       = filepath.Join(t.TempDir(), "out")
+		existing  = filepath.Join(dir, "existing.txt")
+		untouched = filepath.Join(dir, "untouched.txt")
+		created   = filepath.Join(dir, "created.txt")
+		nested    = filepath.Join(dir, "a", "b", "nested.txt")
+		emptyDir  = filepath.Join(dir, "d", "e")
+		keptDir   = filepath.Join(dir, "kept")
+	)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for file, data := range map[string]string{existing: "old", untouched: "user"} {
+		if err := os.WriteFile(file, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var id, err = CreateBackup(dir, []string{existing, created, nested}, []string{emptyDir, keptDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Execute.
+	for _, d := range []string{filepath.Dir(nested), emptyDir, keptDir} {
+		if err = os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{existing, created, nested, filepath.Join(keptDir, "user.txt")} {
+		if err = os.WriteFile(file, []byte("new"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = RestoreBackup(id); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, existing, "old")
+	checkFile(t, untouched, "user")
+	checkFile(t, created, "")
+	checkFile(t, nested, "")
+	checkFile(t, filepath.Join(keptDir, "user.txt"), "new")
+	if fi, err := os.Stat(existing); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("restored file mode: got %v, %v", fi, err)
+	}
+	for _, d := range []string{filepath.Join(dir, "a"), filepath.Join(dir, "d")} {
+		if _, err = os.Stat(d); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("created dir %s not removed: %v", d, err)
+		}
+	}
+
+	// Restoring again gives the same result.
+	if err = RestoreBackup(id); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, existing, "old")
+
+	var backups []*Backup
+	if backups, err = ListBackups(); err != nil || len(backups) != 1 || backups[0].ID != id || backups[0].Dir != dir {
+		t.Fatalf("list backups: got %v, %v", backups, err)
+	}
+	if _, err = OpenBackup("missing"); !errors.Is(err, ErrBackupNotFound) {
+		t.Errorf("open missing backup: got %v", err)
+	}
+	if _, err = OpenBackup(".."); err == nil {
+		t.Error("open invalid backup id: expected error")
+	}
+
+	var second string
+	if second, err = CreateBackup(dir, []string{existing}, nil); err != nil {
+		t.Fatal(err)
+	}
+	var pruned []*Backup
+	if pruned, err = PruneBackups(1, time.Time{}); err != nil || len(pruned) != 1 || pruned[0].ID != id {
+		t.Errorf("prune backups: got %v, %v", pruned, err)
+	}
+	if err = RemoveBackup(second); err != nil {
+		t.Fatal(err)
+	}
+	if backups, err = ListBackups(); err != nil || len(backups) != 0 {
+		t.Errorf("list backups after remove: got %v, %v", backups, err)
+	}
+}
+
+func TestExecutorRestoreBackup(t *testing.T) {
+
+	useTempDataHome(t)
+
+	var (
+		args    []string
+		exec, _ = newTestExecutor(fstest.MapFS{
+			"app/boil.json": {Data: []byte(`{
+				"directories": [{"path": "docs"}],
+				"files": [{"path": "a.txt"}, {"path": "new.txt"}, {"path": "sub/b.txt"}]
+			}`)},
+			"app/a.txt":     {Data: []byte(`a`)},
+			"app/new.txt":   {Data: []byte(`new`)},
+			"app/sub/b.txt": {Data: []byte(`{{template "missing"}}`)},
+		}, nil, &args)
+		dir = t.TempDir()
+	)
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("user"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exec.Output = DiskFS{}
+	exec.MakeBackups = true
+	exec.Overwrite = true
+	if err := exec.Execute("app", dir); err == nil {
+		t.Fatal("expected execution error")
+	}
+
+	checkFile(t, filepath.Join(dir, "a.txt"), "user")
+	checkFile(t, filepath.Join(dir, "new.txt"), "")
+	var entries, err = os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("output dir not restored: got %v, %v", entries, err)
+	}
+}
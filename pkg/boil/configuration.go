@@ -23,6 +23,16 @@ const (
 	ConfigFilename = "config.json"
 	// RepositoryDir is default Boil repository irectory name.
 	RepositoryDir = "repository"
+	// BackupDir is default Boil backup directory name.
+	BackupDir = "backups"
+	// GitCacheDir is default Boil git repository cache directory name.
+	GitCacheDir = "git"
+	// DefaultRepositoryName is the name of the default repository in the
+	// repository search path.
+	DefaultRepositoryName = "default"
+	// OverrideRepositoryName is the name of the repository given on command
+	// line in the repository search path.
+	OverrideRepositoryName = "override"
 )
 
 // DefaultConfigFilename returns the absolute path of default config filename.
@@ -40,6 +50,18 @@ func DefaultRepositoryDir() string {
 	return filepath.Join(DefaultConfigDir(), RepositoryDir)
 }
 
+// DefaultBackupDir returns the absolute path of default backup directory.
+// Backups are stored in the user data directory.
+func DefaultBackupDir() string {
+	return filepath.Join(xdg.DataHome, ConfigDir, BackupDir)
+}
+
+// DefaultGitCacheDir returns the absolute path of default directory where git
+// repositories are cloned to. Clones are stored in the user cache directory.
+func DefaultGitCacheDir() string {
+	return filepath.Join(xdg.CacheHome, ConfigDir, GitCacheDir)
+}
+
 // DefaultConfig returns a config set to "sane" defaults or an error.
 // Sane as in:
 // * Author name from user account.
@@ -76,11 +98,27 @@ func DefaultConfig() (config *Config, err error) {
 
 // Config represents Boil configuration file.
 type Config struct {
+	// Schema is an optional JSON Schema reference used by editors.
+	// See ConfigSchema.
+	Schema string `json:"$schema,omitempty"`
 	// Author is the default template author info.
 	Author Author `json:"author,omitempty"`
 	// RepositoryPath is the absolute path to the default repository.
+	//
+	// The default repository is the first repository in the search path and
+	// the one new templates are created in. It is addressed by the name
+	// "default" in template paths, i.e. "default:apps/app".
 	RepositoryPath string `json:"repositoryPath"`
 
+	// Repositories is an ordered list of additional repositories which are
+	// searched for templates, in order, after the default repository.
+	//
+	// A template path is resolved against each repository in the search path
+	// and the first repository that contains the template is used unless the
+	// template path is prefixed with a repository name and a colon, i.e.
+	// "team:apps/app", in which case only the named repository is used.
+	Repositories []*RepositoryDefinition `json:"repositories,omitempty"`
+
 	// DisableBackup, if true disables output directory backup before
 	// Template execution.
 	//
@@ -88,6 +126,10 @@ type Config struct {
 	// the output directory might contain an incomplete and invalid output.
 	DisableBackup bool `json:"disableBackup"`
 
+	// Strict, if true, rejects Metafiles that contain fields not defined by
+	// Metafile, i.e. misspelled field names. See StrictRepository.
+	Strict bool `json:"strict,omitempty"`
+
 	// Editor defines the action to execute for the "edit" command, i.e.
 	// an external application to edit the template files and metafile.
 	//
@@ -100,10 +142,14 @@ type Config struct {
 	Overrides struct {
 		// ConfigFile is the absolute path of loaded config file.
 		ConfigFile string
-		// RepositoryPath is the absolute path of loaded repository.
+		// RepositoryPath is the path of the repository given on command line.
+		// If set it is searched first, before the default repository, and
+		// is addressed by the name "override".
 		RepositoryPath string
 		// DisableBackup overrides the Configuration.DisableBackup.
 		DisableBackup bool
+		// Strict overrides the Configuration.Strict.
+		Strict bool
 		// NoRepository if true does not use a repository when interpreting template
 		// paths. Relative paths will be treated as relative to current working
 		// directory.
@@ -126,7 +172,11 @@ type Config struct {
 func (self *Config) Print() {
 	var wr = tabwriter.NewWriter(os.Stdout, 2, 2, 2, 32, 0)
 	fmt.Fprintf(wr, "RepositoryPath\t%s\n", self.GetRepositoryPath())
+	for _, def := range self.GetRepositories() {
+		fmt.Fprintf(wr, "Repository.%s\t%s\n", def.Name, def.Path)
+	}
 	fmt.Fprintf(wr, "DisableBackup\t%t\n", self.DisableBackup)
+	fmt.Fprintf(wr, "Strict\t%t\n", self.Strict)
 	fmt.Fprintf(wr, "Author.Name\t%s\n", self.Author.Name)
 	fmt.Fprintf(wr, "Author.Email\t%s\n", self.Author.Email)
 	fmt.Fprintf(wr, "Author.Homepage\t%s\n", self.Author.Homepage)
@@ -210,11 +260,14 @@ func (self *Config) SaveToFile(filename string) (err error) {
 }
 
 // ShouldBackup returns true if self says that a backups should be performed.
-func (self *Config) ShouldBackup() (should bool) {
-	if should = !self.Overrides.DisableBackup; !should {
-		should = !self.DisableBackup
-	}
-	return
+func (self *Config) ShouldBackup() bool {
+	return !self.Overrides.DisableBackup && !self.DisableBackup
+}
+
+// IsStrict returns true if Metafiles should be loaded in strict mode
+// considering override values.
+func (self *Config) IsStrict() bool {
+	return self.Overrides.Strict || self.Strict
 }
 
 // GetRepositoryPath returns the RepositoryPath considering override values.
@@ -224,3 +277,32 @@ func (self *Config) GetRepositoryPath() string {
 	}
 	return self.RepositoryPath
 }
+
+// GetRepositories returns the repository search path considering override
+// values. The override repository comes first, followed by the default
+// repository and repositories defined in Repositories in order.
+func (self *Config) GetRepositories() (result []*RepositoryDefinition) {
+	if self.Overrides.RepositoryPath != "" {
+		result = append(result, &RepositoryDefinition{
+			Name: OverrideRepositoryName,
+			Path: self.Overrides.RepositoryPath,
+		})
+	}
+	if self.RepositoryPath != "" {
+		result = append(result, &RepositoryDefinition{
+			Name: DefaultRepositoryName,
+			Path: self.RepositoryPath,
+		})
+	}
+	return append(result, self.Repositories...)
+}
+
+// RepositoryDefinition defines a repository in the repository search path.
+type RepositoryDefinition struct {
+	// Name is the repository name. It is used to address a template in a
+	// specific repository using a "name:path" template path format. Names
+	// must be unique in the search path.
+	Name string `json:"name"`
+	// Path is the repository location given to OpenRepository.
+	Path string `json:"path"`
+}
@@ -0,0 +1,240 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ConflictPolicy defines how a Template output file that already exists in
+// the output directory is handled.
+type ConflictPolicy string
+
+const (
+	// ConflictFail fails the execution before anything is written.
+	// It is the default policy.
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictSkip leaves the existing file unchanged.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing file with Template output.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictAppend appends Template output to the existing file.
+	ConflictAppend ConflictPolicy = "append"
+	// ConflictPrompt asks a ConflictResolver for a policy for the file.
+	ConflictPrompt ConflictPolicy = "prompt"
+	// ConflictNew leaves the existing file unchanged and writes Template
+	// output to a side file named after the file with a SideFileExt suffix.
+	ConflictNew ConflictPolicy = "boilnew"
+	// ConflictMerge merges Template output into the existing file using a
+	// three-way merge. Regions changed on both sides differently are written
+	// with conflict markers.
+	ConflictMerge ConflictPolicy = "merge"
+)
+
+// SideFileExt is the extension appended to output file names by ConflictNew.
+const SideFileExt = ".boilnew"
+
+// ConflictPolicies lists all valid ConflictPolicy values.
+var ConflictPolicies = []ConflictPolicy{
+	ConflictFail,
+	ConflictSkip,
+	ConflictOverwrite,
+	ConflictAppend,
+	ConflictPrompt,
+	ConflictNew,
+	ConflictMerge,
+}
+
+// ParseConflictPolicy returns a ConflictPolicy parsed from s or an error if
+// s is not a valid policy name. An empty s parses as ConflictFail.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	if s == "" {
+		return ConflictFail, nil
+	}
+	for _, policy := range ConflictPolicies {
+		if string(policy) == s {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid conflict policy '%s'", s)
+}
+
+// ConflictRule defines a ConflictPolicy for output files matching a pattern.
+// See Metafile.Conflicts.
+type ConflictRule struct {
+	// Pattern is a path.Match pattern matched against the output file path
+	// relative to the output directory, in slash format. If Pattern contains
+	// no slashes it is matched against the file base name.
+	Pattern string `json:"pattern"`
+	// Policy is the name of the ConflictPolicy for matching files.
+	Policy ConflictPolicy `json:"policy"`
+}
+
+// Match returns true if relPath, an output path relative to the output
+// directory, matches self.Pattern.
+func (self *ConflictRule) Match(relPath string) bool {
+	relPath = strings.TrimPrefix(relPath, "./")
+	if !strings.Contains(self.Pattern, "/") {
+		relPath = path.Base(relPath)
+	}
+	var match, _ = path.Match(self.Pattern, relPath)
+	return match
+}
+
+// ConflictRules is a list of *ConflictRule.
+type ConflictRules []*ConflictRule
+
+// PolicyFor returns the policy of the first rule in self that matches relPath
+// or an empty policy if no rule matches. See ConflictRule.Match.
+func (self ConflictRules) PolicyFor(relPath string) ConflictPolicy {
+	for _, rule := range self {
+		if rule.Match(relPath) {
+			return rule.Policy
+		}
+	}
+	return ""
+}
+
+// ConflictResolver resolves ConflictPrompt policies during execution.
+type ConflictResolver interface {
+	// ResolveConflict returns a policy for the file at target that exists with
+	// current content and would be written with rendered content. Returned
+	// policy may not be ConflictPrompt.
+	ResolveConflict(target string, current, rendered []byte) (ConflictPolicy, error)
+}
+
+// ConflictResolverFunc is a function that implements ConflictResolver.
+type ConflictResolverFunc func(target string, current, rendered []byte) (ConflictPolicy, error)
+
+// ResolveConflict implements ConflictResolver.ResolveConflict by calling self.
+func (self ConflictResolverFunc) ResolveConflict(target string, current, rendered []byte) (ConflictPolicy, error) {
+	return self(target, current, rendered)
+}
+
+// Merge3 merges changes from base to ours and from base to theirs, line by
+// line, and returns the result and the number of conflicting regions.
+//
+// Regions changed in only one of ours or theirs take that change, regions
+// changed equally in both take either. Regions changed differently are
+// written with "<<<<<<< current", "=======" and ">>>>>>> template" markers
+// around ours and theirs respectively.
+//
+// If base is nil, lines common to ours and theirs are used as the base so
+// that lines added on either side are kept and only regions that differ on
+// both sides conflict.
+func Merge3(base, ours, theirs []byte) (result []byte, conflicts int) {
+
+	var (
+		o = splitLines(ours)
+		t = splitLines(theirs)
+		b []string
+	)
+	if base == nil {
+		for _, edit := range diffLines(o, t) {
+			if edit.op == ' ' {
+				b = append(b, edit.line)
+			}
+		}
+	} else {
+		b = splitLines(base)
+	}
+
+	var (
+		matchO = matchLines(b, o)
+		matchT = matchLines(b, t)
+		out    strings.Builder
+		bi     int
+		oi     int
+		ti     int
+	)
+	for bi <= len(b) {
+		// Copy stable lines, unchanged in both.
+		if bi < len(b) && matchO[bi] == oi && matchT[bi] == ti {
+			out.WriteString(b[bi])
+			bi, oi, ti = bi+1, oi+1, ti+1
+			continue
+		}
+		// Find the next stable line.
+		var next = bi
+		for next < len(b) && (matchO[next] < 0 || matchT[next] < 0) {
+			next++
+		}
+		var oEnd, tEnd = len(o), len(t)
+		if next < len(b) {
+			oEnd, tEnd = matchO[next], matchT[next]
+		}
+		var (
+			bChunk = b[bi:next]
+			oChunk = o[oi:oEnd]
+			tChunk = t[ti:tEnd]
+		)
+		switch {
+		case equalLines(oChunk, bChunk):
+			out.WriteString(strings.Join(tChunk, ""))
+		case equalLines(tChunk, bChunk), equalLines(oChunk, tChunk):
+			out.WriteString(strings.Join(oChunk, ""))
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< current\n")
+			writeTerminatedLines(&out, oChunk)
+			out.WriteString("=======\n")
+			writeTerminatedLines(&out, tChunk)
+			out.WriteString(">>>>>>> template\n")
+		}
+		if next == len(b) {
+			break
+		}
+		bi, oi, ti = next, oEnd, tEnd
+	}
+
+	return []byte(out.String()), conflicts
+}
+
+// matchLines returns a slice that maps each line of a to the index of the
+// equal line in b on the shortest edit path from a to b, or -1 if the line
+// was deleted.
+func matchLines(a, b []string) (match []int) {
+	match = make([]int, len(a))
+	var ai, bi int
+	for _, edit := range diffLines(a, b) {
+		switch edit.op {
+		case ' ':
+			match[ai] = bi
+			ai, bi = ai+1, bi+1
+		case '-':
+			match[ai] = -1
+			ai++
+		case '+':
+			bi++
+		}
+	}
+	return
+}
+
+// equalLines returns true if a and b are equal.
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTerminatedLines writes lines to out terminating a last unterminated
+// line.
+func writeTerminatedLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			out.WriteString("\n")
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestConflictRules(t *testing.T) {
+	if p, err := ParseConflictPolicy(""); err != nil || p != ConflictFail {
+		t.Errorf("empty policy: got %q, %v", p, err)
+	}
+	for _, policy := range ConflictPolicies {
+		if p, err := ParseConflictPolicy(string(policy)); err != nil || p != policy {
+			t.Errorf("%s: got %q, %v", policy, p, err)
+		}
+	}
+	if _, err := ParseConflictPolicy("keep"); err == nil {
+		t.Error("expected invalid policy error")
+	}
+
+	var rules = ConflictRules{
+		{Pattern: "cmd/*/main.go", Policy: ConflictSkip},
+		{Pattern: "*.md", Policy: ConflictMerge},
+		{Pattern: "go.*", Policy: ConflictNew},
+	}
+	for path, want := range map[string]ConflictPolicy{
+		"cmd/app/main.go":   ConflictSkip,
+		"./cmd/app/main.go": ConflictSkip,
+		"main.go":           "",
+		"README.md":         ConflictMerge,
+		"docs/guide.md":     ConflictMerge,
+		"go.mod":            ConflictNew,
+	} {
+		if policy := rules.PolicyFor(path); policy != want {
+			t.Errorf("%s: got %q, want %q", path, policy, want)
+		}
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	for _, test := range []struct {
+		name               string
+		base, ours, theirs string
+		nilBase            bool
+		result             string
+		conflicts          int
+	}{
+		{
+			name: "unchanged",
+			base: "a\nb\n", ours: "a\nb\n", theirs: "a\nb\n",
+			result: "a\nb\n",
+		},
+		{
+			name: "changed separately",
+			base: "a\nb\nc\nd\ne\n", ours: "A\nb\nc\nd\ne\n", theirs: "a\nb\nc\nd\nE\n",
+			result: "A\nb\nc\nd\nE\n",
+		},
+		{
+			name: "changed equally",
+			base: "a\nb\nc\n", ours: "a\nB\nc\n", theirs: "a\nB\nc\n",
+			result: "a\nB\nc\n",
+		},
+		{
+			name: "added and removed",
+			base: "a\nb\nc\n", ours: "a\nb\nc\nd\n", theirs: "b\nc\n",
+			result: "b\nc\nd\n",
+		},
+		{
+			name: "conflict",
+			base: "a\nb\nc\n", ours: "a\nours\nc\n", theirs: "a\ntheirs\nc\n",
+			result:    "a\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> template\nc\n",
+			conflicts: 1,
+		},
+		{
+			name: "conflict without terminator",
+			base: "a\nb", ours: "a\nours", theirs: "a\ntheirs",
+			result:    "a\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> template\n",
+			conflicts: 1,
+		},
+		{
+			name: "two conflicts",
+			base: "a\nb\nc\nd\ne\n", ours: "1\nb\nc\nd\n2\n", theirs: "3\nb\nc\nd\n4\n",
+			result:    "<<<<<<< current\n1\n=======\n3\n>>>>>>> template\nb\nc\nd\n<<<<<<< current\n2\n=======\n4\n>>>>>>> template\n",
+			conflicts: 2,
+		},
+		{
+			name: "no base keeps additions",
+			ours: "a\nuser\nb\n", theirs: "a\nb\ntemplate\n", nilBase: true,
+			result: "a\nuser\nb\ntemplate\n",
+		},
+		{
+			name: "no base conflict",
+			ours: "a\nuser\n", theirs: "a\ntemplate\n", nilBase: true,
+			result:    "a\n<<<<<<< current\nuser\n=======\ntemplate\n>>>>>>> template\n",
+			conflicts: 1,
+		},
+	} {
+		var base = []byte(test.base)
+		if test.nilBase {
+			base = nil
+		}
+		var result, conflicts = Merge3(base, []byte(test.ours), []byte(test.theirs))
+		if string(result) != test.result || conflicts != test.conflicts {
+			t.Errorf("%s: got %d conflicts\n%s\nwant %d\n%s", test.name, conflicts, result, test.conflicts, test.result)
+		}
+	}
+}
+
+func TestExecutorConflictPolicies(t *testing.T) {
+
+	var fsys = fstest.MapFS{
+		"app/boil.json": {Data: []byte(`{
+			"files": [{"path": "main.go"}, {"path": "readme.md"}],
+			"conflicts": [{"pattern": "*.md", "policy": "skip"}]
+		}`)},
+		"app/main.go":   {Data: []byte("a\nb\nc\n")},
+		"app/readme.md": {Data: []byte("template readme\n")},
+	}
+
+	var (
+		out    = filepath.Join(string(filepath.Separator), "out")
+		main   = filepath.Join(out, "main.go")
+		readme = filepath.Join(out, "readme.md")
+	)
+	for _, test := range []struct {
+		policy   ConflictPolicy
+		resolved ConflictPolicy
+		current  string
+		result   string
+		side     string
+		fail     bool
+		conflict bool
+	}{
+		{policy: "", current: "user\n", fail: true},
+		{policy: ConflictFail, current: "user\n", fail: true},
+		{policy: ConflictSkip, current: "user\n", result: "user\n"},
+		{policy: ConflictOverwrite, current: "user\n", result: "a\nb\nc\n"},
+		{policy: ConflictAppend, current: "user\n", result: "user\na\nb\nc\n"},
+		{policy: ConflictNew, current: "user\n", result: "user\n", side: "a\nb\nc\n"},
+		{policy: ConflictMerge, current: "a\nb\nc\nuser\n", result: "a\nb\nc\nuser\n"},
+		{policy: ConflictMerge, current: "a\nuser\nc\n",
+			result: "a\n<<<<<<< current\nuser\n=======\nb\n>>>>>>> template\nc\n", conflict: true},
+		{policy: ConflictPrompt, current: "user\n", fail: true},
+		{policy: ConflictPrompt, resolved: ConflictOverwrite, current: "user\n", result: "a\nb\nc\n"},
+		{policy: ConflictPrompt, resolved: ConflictNew, current: "user\n", result: "user\n", side: "a\nb\nc\n"},
+		{policy: ConflictPrompt, resolved: ConflictFail, current: "user\n", fail: true},
+	} {
+		var (
+			args         []string
+			exec, output = newTestExecutor(fsys, nil, &args)
+		)
+		if err := output.MkdirAll(out, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range map[string]string{main: test.current, readme: "user readme\n"} {
+			if err := output.WriteFile(name, []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		exec.OnConflict = test.policy
+		if test.resolved != "" {
+			exec.Resolver = ConflictResolverFunc(func(target string, current, rendered []byte) (ConflictPolicy, error) {
+				return test.resolved, nil
+			})
+		}
+
+		var name = string(test.policy) + "/" + string(test.resolved)
+		if err := exec.Execute("app", out); err != nil {
+			if !test.fail {
+				t.Errorf("%s: %v", name, err)
+			}
+			continue
+		} else if test.fail {
+			t.Errorf("%s: expected error", name)
+			continue
+		}
+		if data, _ := output.ReadFile(main); string(data) != test.result {
+			t.Errorf("%s: got %q, want %q", name, data, test.result)
+		}
+		var data, err = output.ReadFile(main + SideFileExt)
+		if test.side == "" && err == nil || test.side != "" && string(data) != test.side {
+			t.Errorf("%s: side file: got %q, %v, want %q", name, data, err, test.side)
+		}
+		if (len(exec.Conflicted) > 0) != test.conflict {
+			t.Errorf("%s: conflicted: got %v", name, exec.Conflicted)
+		}
+		// The conflict rule of the Metafile takes precedence.
+		if data, _ := output.ReadFile(readme); string(data) != "user readme\n" {
+			t.Errorf("%s: readme: got %q", name, data)
+		}
+	}
+}
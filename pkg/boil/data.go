@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"text/template"
 
 	"github.com/vedranvuk/bast/pkg/bast"
 )
@@ -23,6 +24,20 @@ func NewData() *Data {
 	}
 }
 
+// FuncMap implements FuncMapper. It returns the boil function library, see
+// FuncMap, with functions of Bast added. Bast functions take precedence over
+// boil functions of the same name. self may be nil.
+func (self *Data) FuncMap() template.FuncMap {
+	var funcs = FuncMap()
+	if self == nil || self.Bast == nil {
+		return funcs
+	}
+	for name, f := range self.Bast.FuncMap() {
+		funcs[name] = f
+	}
+	return funcs
+}
+
 // StringVar returns a variable value if it exists and its value is a string.
 func (self *Data) StringVar(name string) string {
 	if v, exists := self.Vars[name]; exists {
@@ -36,6 +51,7 @@ func (self *Data) StringVar(name string) string {
 func DataFromInputs(vars Variables, goInput, jsonInput []string) (out *Data, err error) {
 	out = new(Data)
 	out.Vars = vars
+	out.Json = make(map[string]any)
 	if out.Bast, err = bast.Load(goInput...); err != nil {
 		return nil, fmt.Errorf("load go: %w", err)
 	}
@@ -0,0 +1,332 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// ChangeStatus describes how a file written to an OverlayFS relates to the
+// file at the same path in the lower layer.
+type ChangeStatus int
+
+const (
+	// ChangeNew is a file that does not exist in the lower layer.
+	ChangeNew ChangeStatus = iota
+	// ChangeModified is a file whose content differs from the lower layer.
+	ChangeModified
+	// ChangeUnchanged is a file whose content equals the lower layer.
+	ChangeUnchanged
+)
+
+// String implements fmt.Stringer.
+func (self ChangeStatus) String() string {
+	switch self {
+	case ChangeNew:
+		return "new"
+	case ChangeModified:
+		return "modified"
+	case ChangeUnchanged:
+		return "unchanged"
+	}
+	return "unknown"
+}
+
+// FileChange is a file written to an OverlayFS.
+type FileChange struct {
+	// Path is the absolute path of the file.
+	Path string
+	// Status is the change status of the file.
+	Status ChangeStatus
+	// Old is the file content in the lower layer, nil if the file is new.
+	Old []byte
+	// New is the file content in the upper layer.
+	New []byte
+}
+
+// Changes returns a FileChange for each file written to self, sorted by path,
+// or an error.
+func (self *OverlayFS) Changes() (changes []*FileChange, err error) {
+	for _, name := range self.upper.Files() {
+		var change = &FileChange{Path: name}
+		if change.New, err = self.upper.ReadFile(name); err != nil {
+			return nil, err
+		}
+		if change.Old, err = self.lower.ReadFile(name); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+			change.Status = ChangeNew
+		} else if bytes.Equal(change.Old, change.New) {
+			change.Status = ChangeUnchanged
+		} else {
+			change.Status = ChangeModified
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// DiffContextLines is the number of unchanged lines shown around changes in
+// a unified diff.
+const DiffContextLines = 3
+
+// WriteUnifiedDiff writes a unified diff of a and b to w using oldName and
+// newName as file names in the header. If a is nil the old file name is
+// written as "/dev/null". Nothing is written if a and b are equal.
+func WriteUnifiedDiff(w io.Writer, oldName, newName string, a, b []byte) (err error) {
+	if a != nil && bytes.Equal(a, b) {
+		return nil
+	}
+	if a == nil {
+		oldName = "/dev/null"
+	}
+	var (
+		edits = diffLines(splitLines(a), splitLines(b))
+		buf   bytes.Buffer
+	)
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+	for _, hunk := range diffHunks(edits, DiffContextLines) {
+		hunk.write(&buf)
+	}
+	_, err = w.Write(buf.Bytes())
+	return
+}
+
+// splitLines splits data into lines that keep their line terminators.
+// The last line has no terminator if data does not end with one.
+func splitLines(data []byte) (lines []string) {
+	for len(data) > 0 {
+		var i = bytes.IndexByte(data, '\n')
+		if i < 0 {
+			lines = append(lines, string(data))
+			break
+		}
+		lines = append(lines, string(data[:i+1]))
+		data = data[i+1:]
+	}
+	return
+}
+
+// diffEdit is a single line of a line diff.
+type diffEdit struct {
+	// op is ' ' for an equal line, '-' for a deleted and '+' for an
+	// inserted line.
+	op byte
+	// line is the line text including the terminator.
+	line string
+}
+
+// diffLines returns the shortest edit script that transforms a into b using
+// the linear space variant of the Myers difference algorithm. Deleted lines
+// precede inserted lines within a changed region.
+func diffLines(a, b []string) (edits []diffEdit) {
+	var differ = &lineDiffer{a: a, b: b}
+	differ.diff(0, len(a), 0, len(b))
+	return differ.edits
+}
+
+// lineDiffer computes a line diff, see diffLines.
+type lineDiffer struct {
+	// a and b are the old and new lines.
+	a, b []string
+	// edits are the produced edits.
+	edits []diffEdit
+}
+
+// diff appends edits that transform a[aLo:aHi] into b[bLo:bHi] by splitting
+// them at the middle snake of their shortest edit path, recursively.
+func (self *lineDiffer) diff(aLo, aHi, bLo, bHi int) {
+
+	// Equal lines at the start and the end are not part of any edit.
+	for aLo < aHi && bLo < bHi && self.a[aLo] == self.b[bLo] {
+		self.edits = append(self.edits, diffEdit{' ', self.a[aLo]})
+		aLo, bLo = aLo+1, bLo+1
+	}
+	var suffix = 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && self.a[aHi-suffix-1] == self.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aHi:
+		for _, line := range self.b[bLo:bHi] {
+			self.edits = append(self.edits, diffEdit{'+', line})
+		}
+	case bLo == bHi:
+		for _, line := range self.a[aLo:aHi] {
+			self.edits = append(self.edits, diffEdit{'-', line})
+		}
+	default:
+		var x, y, u, v = self.middleSnake(aLo, aHi, bLo, bHi)
+		self.diff(aLo, x, bLo, y)
+		for _, line := range self.a[x:u] {
+			self.edits = append(self.edits, diffEdit{' ', line})
+		}
+		self.diff(u, aHi, v, bHi)
+	}
+
+	for _, line := range self.a[aHi : aHi+suffix] {
+		self.edits = append(self.edits, diffEdit{' ', line})
+	}
+}
+
+// middleSnake returns the start x, y and the end u, v of the middle snake of
+// the shortest edit path from a[aLo:aHi] to b[bLo:bHi], found by searching
+// from both ends at once. The ranges must not be empty and must differ in
+// their first and last lines, so the path has at least two edits and both
+// halves it is split into are shorter.
+func (self *lineDiffer) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+
+	var (
+		n, m   = aHi - aLo, bHi - bLo
+		delta  = n - m
+		odd    = delta%2 != 0
+		max    = (n + m + 1) / 2
+		offset = max + 1
+		// forward holds furthest x of forward paths on each diagonal k,
+		// backward holds furthest distance from the end of backward paths
+		// on each diagonal delta-k.
+		forward  = make([]int, 2*max+3)
+		backward = make([]int, 2*max+3)
+	)
+
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y = x - k
+			u, v = x, y
+			for u < n && v < m && self.a[aLo+u] == self.b[bLo+v] {
+				u, v = u+1, v+1
+			}
+			forward[offset+k] = u
+			if r := delta - k; odd && r >= -(d-1) && r <= d-1 && u+backward[offset+r] >= n {
+				return aLo + x, bLo + y, aLo + u, bLo + v
+			}
+		}
+		for r := -d; r <= d; r += 2 {
+			var rx int
+			if r == -d || (r != d && backward[offset+r-1] < backward[offset+r+1]) {
+				rx = backward[offset+r+1]
+			} else {
+				rx = backward[offset+r-1] + 1
+			}
+			var ry = rx - r
+			u, v = n-rx, m-ry
+			for rx < n && ry < m && self.a[aHi-rx-1] == self.b[bHi-ry-1] {
+				rx, ry = rx+1, ry+1
+			}
+			backward[offset+r] = rx
+			if k := delta - r; !odd && k >= -d && k <= d && forward[offset+k]+rx >= n {
+				return aLo + n - rx, bLo + m - ry, aLo + u, bLo + v
+			}
+		}
+	}
+
+	panic("diff: no middle snake")
+}
+
+// diffHunk is a group of edits with surrounding context.
+type diffHunk struct {
+	// oldStart and newStart are 1-based starting line numbers.
+	oldStart, newStart int
+	// oldLines and newLines are line counts in old and new files.
+	oldLines, newLines int
+	// edits are the hunk edits.
+	edits []diffEdit
+}
+
+// write writes the hunk in unified format to w.
+func (self *diffHunk) write(w io.Writer) {
+	fmt.Fprintf(w, "@@ -%s +%s @@\n",
+		hunkRange(self.oldStart, self.oldLines),
+		hunkRange(self.newStart, self.newLines),
+	)
+	for _, edit := range self.edits {
+		fmt.Fprintf(w, "%c%s", edit.op, edit.line)
+		if !strings.HasSuffix(edit.line, "\n") {
+			fmt.Fprintf(w, "\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk line range.
+func hunkRange(start, lines int) string {
+	if lines == 0 {
+		start--
+	}
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// diffHunks groups edits into hunks with context lines of unchanged lines
+// around changes. Changes separated by no more than 2*context unchanged
+// lines are merged into a single hunk.
+func diffHunks(edits []diffEdit, context int) (hunks []*diffHunk) {
+
+	// Line numbers in old and new files preceding each edit.
+	var oldPos, newPos = make([]int, len(edits)), make([]int, len(edits))
+	for i, o, n := 0, 0, 0; i < len(edits); i++ {
+		oldPos[i], newPos[i] = o, n
+		if edits[i].op != '+' {
+			o++
+		}
+		if edits[i].op != '-' {
+			n++
+		}
+	}
+
+	// Group changed edit indexes into ranges including context.
+	var start, end, last = -1, -1, -1
+	var flush = func() {
+		if start < 0 {
+			return
+		}
+		var hunk = &diffHunk{
+			oldStart: oldPos[start] + 1,
+			newStart: newPos[start] + 1,
+			edits:    edits[start:end],
+		}
+		for _, edit := range hunk.edits {
+			if edit.op != '+' {
+				hunk.oldLines++
+			}
+			if edit.op != '-' {
+				hunk.newLines++
+			}
+		}
+		hunks = append(hunks, hunk)
+	}
+	for i, edit := range edits {
+		if edit.op == ' ' {
+			continue
+		}
+		if start < 0 || i-last-1 > 2*context {
+			flush()
+			if start = i - context; start < 0 {
+				start = 0
+			}
+		}
+		if end = i + context + 1; end > len(edits) {
+			end = len(edits)
+		}
+		last = i
+	}
+	flush()
+
+	return
+}
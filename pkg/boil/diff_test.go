@@ -0,0 +1,156 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	var prev, cur = make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// checkEdits fails t if edits do not transform a into b with the least
+// number of changed lines.
+func checkEdits(t *testing.T, a, b []string, edits []diffEdit) {
+	t.Helper()
+	var old, new []string
+	var changes int
+	for _, edit := range edits {
+		if edit.op != '+' {
+			old = append(old, edit.line)
+		}
+		if edit.op != '-' {
+			new = append(new, edit.line)
+		}
+		if edit.op != ' ' {
+			changes++
+		}
+	}
+	if !equalLines(old, a) || !equalLines(new, b) {
+		t.Fatalf("edits do not transform %q into %q: %v", a, b, edits)
+	}
+	if want := len(a) + len(b) - 2*lcsLength(a, b); changes != want {
+		t.Fatalf("%q -> %q: got %d changes, want %d", a, b, changes, want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	var random = rand.New(rand.NewSource(1))
+	var lines = func(n int) (out []string) {
+		for i := 0; i < n; i++ {
+			out = append(out, string(rune('a'+random.Intn(4)))+"\n")
+		}
+		return
+	}
+	for _, test := range [][2][]string{
+		{nil, nil},
+		{nil, {"a\n"}},
+		{{"a\n"}, nil},
+		{{"a\n", "b\n", "c\n"}, {"a\n", "b\n", "c\n"}},
+		{{"a\n", "b\n", "c\n"}, {"a\n", "x\n", "c\n"}},
+		{{"a\n", "b\n", "c\n", "a\n", "b\n", "b\n", "a\n"}, {"c\n", "b\n", "a\n", "b\n", "a\n", "c\n"}},
+	} {
+		checkEdits(t, test[0], test[1], diffLines(test[0], test[1]))
+	}
+	for i := 0; i < 1000; i++ {
+		var a, b = lines(random.Intn(20)), lines(random.Intn(20))
+		checkEdits(t, a, b, diffLines(a, b))
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	// Unrelated files of this size needed gigabytes of memory when all
+	// steps of the search were kept for backtracking.
+	var a, b []string
+	for i := 0; i < 10000; i++ {
+		a = append(a, fmt.Sprintf("a%d\n", i))
+		b = append(b, fmt.Sprintf("b%d\n", i))
+	}
+	var edits = diffLines(a, b)
+	if len(edits) != 20000 || edits[0].op != '-' || edits[19999].op != '+' {
+		t.Fatalf("unexpected edits of unrelated files")
+	}
+	b = append(append([]string{}, a[:5000]...), append([]string{"x\n"}, a[5001:]...)...)
+	checkEdits(t, a, b, diffLines(a, b))
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		out  string
+	}{
+		{"a\n", "a\n", ""},
+		{"", "", ""},
+		{"a\nb\nc\n", "a\nx\nc\n", "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		{"a\n", "a", "--- old\n+++ new\n@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n"},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"1\nx\n3\n4\n5\n6\n7\n8\n9\n10\ny\n12\n",
+			"--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-2\n+x\n 3\n 4\n 5\n@@ -8,5 +8,5 @@\n 8\n 9\n 10\n-11\n+y\n 12\n",
+		},
+	} {
+		var buf bytes.Buffer
+		if err := WriteUnifiedDiff(&buf, "old", "new", []byte(test.a), []byte(test.b)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.out {
+			t.Errorf("%q -> %q: got\n%s\nwant\n%s", test.a, test.b, buf.String(), test.out)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteUnifiedDiff(&buf, "old", "new", nil, []byte("a\n")); err != nil {
+		t.Fatal(err)
+	}
+	if want := "--- /dev/null\n+++ new\n@@ -0,0 +1 @@\n+a\n"; buf.String() != want {
+		t.Errorf("new file: got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestMerge3RoundTrip(t *testing.T) {
+	var random = rand.New(rand.NewSource(2))
+	var text = func() string {
+		var lines []string
+		for i := random.Intn(30); i > 0; i-- {
+			lines = append(lines, fmt.Sprintf("line %d\n", random.Intn(10)))
+		}
+		return strings.Join(lines, "")
+	}
+	for i := 0; i < 500; i++ {
+		var base, changed = []byte(text()), []byte(text())
+		// A side equal to the base takes the other side.
+		for _, test := range [][3][]byte{
+			{base, base, changed},
+			{base, changed, base},
+			{base, changed, changed},
+			{nil, changed, changed},
+		} {
+			var result, conflicts = Merge3(test[0], test[1], test[2])
+			if conflicts != 0 || !bytes.Equal(result, changed) {
+				t.Fatalf("merge %q, %q, %q: got %q with %d conflicts, want %q", test[0], test[1], test[2], result, conflicts, changed)
+			}
+		}
+	}
+}
@@ -5,7 +5,6 @@
 package boil
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -15,17 +14,22 @@ import (
 )
 
 // NewDiskRepository returns a new DiskRepository rooted at root.
-func NewDiskRepository(root string) *DiskRepository { return &DiskRepository{root} }
+func NewDiskRepository(root string) *DiskRepository { return &DiskRepository{root: root} }
 
 // DiskRepository is a repository that works with a local fileystem.
 // It is initialized from an absolute filesystem path or a path relative to the
 // current working directory.
 type DiskRepository struct {
 	root string
+	// strict if true rejects Metafiles with unknown fields.
+	strict bool
 }
 
 func (self DiskRepository) Location() string { return self.root }
 
+// SetStrict implements StrictRepository.SetStrict.
+func (self *DiskRepository) SetStrict(strict bool) { self.strict = strict }
+
 // LoadMetamap implements Repository.LoadMetamap.
 func (self DiskRepository) LoadMetamap() (metamap Metamap, err error) {
 	var metadata *Metafile
@@ -39,13 +43,12 @@ func (self DiskRepository) LoadMetamap() (metamap Metamap, err error) {
 			return fmt.Errorf("walk error: %w", err)
 		}
 
-		if metadata, err = readMeta(filepath.Join(path, MetafileName)); err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				return err
-			}
+		var format MetafileFormat
+		if format, err = findMeta(path); err != nil || format == "" {
+			return err
 		}
-		if metadata == nil {
-			return nil
+		if metadata, err = readMeta(path, format, self.strict); err != nil {
+			return err
 		}
 		if metadata.Path, err = filepath.Rel(self.root, path); err != nil {
 			return fmt.Errorf("rel failed: %w", err)
@@ -57,14 +60,7 @@ func (self DiskRepository) LoadMetamap() (metamap Metamap, err error) {
 		} else {
 			key = "."
 		}
-		metamap[key] = metadata
-
-		if metadata.Groups == nil {
-			return nil
-		}
-		for _, multi := range metadata.Groups {
-			metamap[fmt.Sprintf("%s#%s", key, multi.Name)] = metadata
-		}
+		metamap.add(key, metadata)
 
 		return nil
 	}); err != nil {
@@ -74,34 +70,55 @@ func (self DiskRepository) LoadMetamap() (metamap Metamap, err error) {
 }
 
 func (self *DiskRepository) HasMeta(path string) (exists bool, err error) {
-	return self.Exists(filepath.Join(path, MetafileName))
+	var format MetafileFormat
+	if format, err = findMeta(filepath.Join(self.root, path)); err != nil {
+		return false, err
+	}
+	return format != "", nil
 }
 
 func (self *DiskRepository) OpenMeta(path string) (meta *Metafile, err error) {
-	if meta, err = readMeta(filepath.Join(self.root, path, MetafileName)); meta != nil {
+	var (
+		dir    = filepath.Join(self.root, path)
+		format MetafileFormat
+	)
+	if format, err = findMeta(dir); err != nil {
+		return nil, fmt.Errorf("openmeta: %w", err)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("openmeta: %w", &fs.PathError{
+			Op:   "open",
+			Path: filepath.Join(dir, MetafileName),
+			Err:  fs.ErrNotExist,
+		})
+	}
+	if meta, err = readMeta(dir, format, self.strict); meta != nil {
 		meta.Path = path
 	}
 	return
 }
 
+// SaveMeta implements Repository.SaveMeta.
+//
+// The Metafile is saved in meta.Format. If it is empty the format of the
+// Metafile that already exists in the Template directory is used or
+// MetafileJSON if there is none.
 func (self *DiskRepository) SaveMeta(meta *Metafile) (err error) {
 	if err = self.Mkdir(meta.Path); err != nil {
 		return
 	}
 
-	var file *os.File
-	if file, err = os.OpenFile(
-		filepath.Join(self.root, meta.Path, MetafileName),
-		os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.ModePerm,
-	); err != nil {
-		return fmt.Errorf("open metafile: %w", err)
+	if meta.Format == "" {
+		if meta.Format, err = findMeta(filepath.Join(self.root, meta.Path)); err != nil {
+			return fmt.Errorf("find metafile: %w", err)
+		}
 	}
 
 	var data []byte
-	if data, err = json.MarshalIndent(meta, "", "\t"); err != nil {
-		return fmt.Errorf("marshal metafile: %w", err)
+	if data, err = MarshalMetafile(meta); err != nil {
+		return
 	}
-	if _, err = file.Write(data); err != nil {
+	if err = os.WriteFile(filepath.Join(self.root, meta.File()), data, os.ModePerm); err != nil {
 		return fmt.Errorf("write metafile: %w", err)
 	}
 
@@ -135,25 +152,40 @@ func (self *DiskRepository) Remove(path string) error {
 }
 
 func (self *DiskRepository) WalkDir(root string, f fs.WalkDirFunc) (err error) {
-	if root, err = filepath.Abs(filepath.Join(self.root, root)); err != nil {
-		return fmt.Errorf("abs repo root: %w", err)
-	}
-	return filepath.WalkDir(self.root, func(path string, d fs.DirEntry, err error) error {
-		if path, err = filepath.Rel(root, path); err != nil {
-			return fmt.Errorf("rel path to repo root: %w", err)
+	return filepath.WalkDir(filepath.Join(self.root, root), func(path string, d fs.DirEntry, err error) error {
+		var rel, e = filepath.Rel(self.root, path)
+		if e != nil {
+			return fmt.Errorf("rel path to repo root: %w", e)
 		}
-		return f(path, d, err)
+		return f(rel, d, err)
 	})
 }
 
-func readMeta(filename string) (meta *Metafile, err error) {
-	var data []byte
+// findMeta returns the format of the Metafile in the directory dir or an
+// empty format if dir does not contain a Metafile. See MetafileFormats.
+func findMeta(dir string) (format MetafileFormat, err error) {
+	for _, format = range MetafileFormats {
+		if _, err = os.Stat(filepath.Join(dir, format.Filename())); err == nil {
+			return format, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// readMeta reads the Metafile in format from the directory dir.
+func readMeta(dir string, format MetafileFormat, strict bool) (meta *Metafile, err error) {
+	var (
+		filename = filepath.Join(dir, format.Filename())
+		data     []byte
+	)
 	if data, err = os.ReadFile(filename); err != nil {
 		return nil, fmt.Errorf("openmeta: %w", err)
 	}
-	meta = new(Metafile)
-	if err = json.Unmarshal(data, meta); err != nil {
-		return nil, fmt.Errorf("unmarshal metafile: %w", err)
+	if meta, err = UnmarshalMetafile(data, format, strict); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
 	}
 	return
 }
@@ -0,0 +1,686 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/vedranvuk/bast/pkg/bast"
+	"github.com/vedranvuk/tmpl"
+)
+
+// NewExecutor returns a new *Executor that executes Templates from repo
+// configured by config.
+//
+// The returned Executor prompts the user on stdin for prompts and conflicts,
+// writes output to the local filesystem using DiskFS, runs actions using
+// DefaultActionRunner, does not log and makes backups if config says so.
+func NewExecutor(config *Config, repo Repository) *Executor {
+	var interrogator = NewInterrogatorPrompter(os.Stdin, os.Stdout)
+	return &Executor{
+		Config:      config,
+		Repository:  repo,
+		Prompter:    interrogator,
+		Resolver:    interrogator,
+		Output:      DiskFS{},
+		Runner:      DefaultActionRunner,
+		Vars:        make(Variables),
+		MakeBackups: config.ShouldBackup(),
+	}
+}
+
+// Executor executes Templates.
+//
+// It loads the Template Metafile and its groups from a Repository, runs the
+// Template actions, presents prompts, loads data and executes Template files
+// into an output directory.
+//
+// Fields configure the execution and may be changed before calling Execute.
+// After Execute returns TemplatePath, OutputDir, Data and Tasks hold the
+// state of the last execution and can be inspected.
+type Executor struct {
+	// Config is the program configuration. Author details and module prefix
+	// are used to set standard variables and prompt defaults.
+	Config *Config
+	// Repository is the Repository Templates are loaded from.
+	Repository Repository
+	// Prompter answers Template prompts.
+	// If nil, prompts are not presented unless NonInteractive is set.
+	Prompter Prompter
+	// NonInteractive if true never presents prompts. Prompts whose variables
+	// are not defined in Vars get their default values and an error is
+	// returned for any required prompt without a default value.
+	NonInteractive bool
+	// Output is the filesystem output is written to.
+	Output OutputFS
+	// Logger, if not nil, receives verbose execution output.
+	Logger io.Writer
+	// Runner runs Template actions.
+	Runner ActionRunner
+	// Resolver resolves ConflictPrompt policies.
+	// If nil, a ConflictPrompt policy fails the execution.
+	Resolver ConflictResolver
+
+	// Vars are the initial variables. Prompts are presented only for
+	// variables that are not defined in Vars. Values of variables defined
+	// by prompts are converted to prompt types and checked.
+	Vars Variables
+	// GoInputs is a list of paths of go files or packages to parse and make
+	// their AST available to template files.
+	GoInputs []string
+	// JsonInputs is a list of paths of json files to parse and make available
+	// to template files.
+	JsonInputs []string
+	// NoMetadata if true disables parsing template metadata and copies the
+	// source template directory recursively to output directory. This
+	// disables groups, prompts and actions.
+	NoMetadata bool
+	// Overwrite, if true specifies that any file matching a Template output
+	// file already existing in the output directory may be overwritten.
+	// It takes precedence over OnConflict and Metafile conflict rules.
+	Overwrite bool
+	// OnConflict is the ConflictPolicy for output files that already exist
+	// and are not matched by a conflict rule of their Template Metafile.
+	// If empty, ConflictFail is used.
+	OnConflict ConflictPolicy
+	// MakeBackups if true creates a backup of the files in the output
+	// directory that are about to be created or overwritten before they are
+	// written and restores it if execution fails. Backups are made only if
+	// Output is DiskFS.
+	MakeBackups bool
+	// Manifest, if not nil, is completed with the Template version, variables
+	// and generated files during execution and written to the output
+	// directory along with base copies of generated files. If its
+	// TemplatePath is empty it is set to the executed Template path.
+	Manifest *Manifest
+	// Record, if not nil, records values of all prompts of the execution in
+	// order, including values of prompts that were not presented because
+	// their variables were defined in Vars. If its TemplatePath is empty it
+	// is set to the executed Template path.
+	Record *Session
+	// Previous, if not nil, is the Manifest of a previous execution into the
+	// output directory. Merges use base copies recorded with Previous as the
+	// common base. It is used to upgrade generated output along with Vars
+	// set to variables recorded in Previous.
+	Previous *Manifest
+
+	// TemplatePath is the path of the last executed Template.
+	TemplatePath string
+	// OutputDir is the absolute path of the last output directory.
+	// It can be changed by a prompt for the OutputDir variable.
+	OutputDir string
+	// Data is the data that Template files were executed with.
+	Data *Data
+	// Tasks are the Tasks of the last execution.
+	Tasks Tasks
+	// Conflicted are the Target paths of files of the last execution that
+	// were merged with conflicts and contain conflict markers.
+	Conflicted []string
+
+	// selected holds optional Group member selections of the last execution
+	// keyed by Group path. See selectMembers.
+	selected Variables
+}
+
+// Execute executes the Template at templatePath in the Repository into
+// outputDir. TemplatePath may address a group in the template using a "#"
+// suffix. If outputDir is empty the current directory is used.
+//
+// If an error occurs it is returned and the operation may be considered
+// failed.
+func (self *Executor) Execute(templatePath, outputDir string) (err error) {
+
+	var printer = self.printer()
+
+	self.TemplatePath = templatePath
+	self.Data = NewData()
+	self.Tasks = nil
+	self.Conflicted = nil
+	self.selected = make(Variables)
+	if self.Record != nil {
+		if self.Record.TemplatePath == "" {
+			self.Record.TemplatePath = templatePath
+		}
+		self.Record.Created = time.Now()
+		self.Record.Answers = nil
+	}
+
+	// Determine absolute output path.
+	if self.OutputDir, err = filepath.Abs(outputDir); err != nil {
+		return fmt.Errorf("get absolute output path: %w", err)
+	}
+	// Produce execution tasks depending on execution mode.
+	switch self.NoMetadata {
+	case false:
+		// Create a Template list, it will contain only the source paths of all
+		// referenced template file paths over all referenced templates in a
+		// possible group. Outputs are determined later after all variables have
+		// been loaded.
+		if self.Tasks, err = TasksFromMetafile(self.Repository, templatePath, self.selectMembers); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("not a boil template: %s", templatePath)
+			}
+			return fmt.Errorf("enumerate template files for execution: %w", err)
+		}
+	case true:
+		if self.Tasks, err = TasksFromWalk(self.Repository, templatePath); err != nil {
+			return fmt.Errorf("enumerate template files for execution: %w", err)
+		}
+	}
+	// Exec pre parse actions.
+	if err = self.Tasks.ExecPreParseActions(self.Runner); err != nil {
+		return fmt.Errorf("pre parse action failed: %w", err)
+	}
+	// Load Data.
+	if self.Data, err = DataFromInputs(
+		make(Variables).AddNew(self.Vars), self.GoInputs, self.JsonInputs,
+	); err != nil {
+		return fmt.Errorf("load data: %w", err)
+	}
+
+	// TODO: Clean up variable expansion.
+
+	// Set vars declared on command line as initial state vars before showing
+	// prompts so variables declared in prompts that have had their value set
+	// early via command line will not be prompted for.
+	self.Data.Vars[VarOutputDir.String()] = self.OutputDir
+	self.Data.Vars[VarTemplatePath.String()] = self.TemplatePath
+	self.Data.Vars[VarAuthorName.String()] = self.Config.Author.Name
+	self.Data.Vars[VarAuthorEmail.String()] = self.Config.Author.Email
+	self.Data.Vars[VarAuthorHomepage.String()] = self.Config.Author.Homepage
+	// Keep member selections with variables so they are stored in a Manifest
+	// and reused on upgrade.
+	for k, v := range self.selected {
+		self.Data.Vars[k] = v
+	}
+	if err = self.convertVars(); err != nil {
+		return fmt.Errorf("convert variables: %w", err)
+	}
+	if (self.Prompter != nil || self.NonInteractive) && !self.NoMetadata {
+		if err = self.presentPrompts(); err != nil {
+			return fmt.Errorf("prompt user: %w", err)
+		}
+	}
+	// If OutputDir was set via Prompt update self.OutputDir.
+	if self.Data.Vars.Exists(VarOutputDir.String()) {
+		if self.Data.Vars[VarOutputDir.String()], err = filepath.Abs(self.Data.StringVar(VarOutputDir.String())); err != nil {
+			return fmt.Errorf("state output dir: %w", err)
+		}
+		self.OutputDir = self.Data.StringVar(VarOutputDir.String())
+	}
+	// Optionally print Bast.
+	if self.Logger != nil && len(self.Data.Bast.Packages) > 0 {
+		printer.Printf("Go input:\n")
+		bast.Print(self.Logger, self.Data.Bast)
+	}
+	// Drop files and directories whose conditions are not met.
+	if err = self.Tasks.FilterConditions(self.Data); err != nil {
+		return fmt.Errorf("evaluate entry conditions: %w", err)
+	}
+	// Now that the vars have been loaded expand variable placeholders in
+	// template paths.
+	if err = self.Tasks.SetTargets(self.OutputDir, self.Data); err != nil {
+		return fmt.Errorf("expand target file names: %w", err)
+	}
+	// Validate templates and optionally check for output conflicts.
+	if !self.NoMetadata {
+		if err = self.Tasks.Validate(self.Repository); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+	}
+	if !self.Overwrite {
+		if err = self.Tasks.SetConflictPolicies(
+			self.Output, self.OutputDir, self.OnConflict,
+		); err != nil {
+			return err
+		}
+	}
+	// Optional verbose output.
+	if self.Logger != nil {
+		printer.Printf("Repository location: %s\n", self.Repository.Location())
+		self.Tasks.Print(printer)
+		self.Data.Vars.Print(printer)
+	}
+	// Exec Pre actions, templates then Post actions.
+	if err = self.Tasks.ExecPreExecuteActions(self.Runner, self.Data); err != nil {
+		return fmt.Errorf("pre execute action failed: %w", err)
+	}
+	if err = self.execute(printer); err != nil {
+		return
+	}
+	if err = self.Tasks.ExecPostExecuteActions(self.Runner, self.Data); err != nil {
+		return fmt.Errorf("post execute action failed: %w", err)
+	}
+	return nil
+}
+
+// convertVars converts values of variables in Data.Vars defined by prompts
+// of all tasks to prompt types. Variables given as strings on the command line
+// or decoded from a Manifest get the types of their prompts this way.
+func (self *Executor) convertVars() (err error) {
+	for _, task := range self.Tasks {
+		if task.Metafile == nil {
+			continue
+		}
+		for _, prompt := range task.Metafile.Prompts {
+			if value, exists := task.Vars[prompt.Variable]; exists {
+				if task.Vars[prompt.Variable], err = prompt.ConvertValue(value); err != nil {
+					return fmt.Errorf("template '%s': %w", task.Metafile.Path, err)
+				}
+			}
+			var value, exists = self.Data.Vars[prompt.Variable]
+			if !exists {
+				continue
+			}
+			if self.Data.Vars[prompt.Variable], err = prompt.ConvertValue(value); err != nil {
+				return
+			}
+		}
+	}
+	return nil
+}
+
+// presentPrompts asks Prompter for a value for each of the prompts defined in
+// metafiles of all tasks, in order as they appear in Tasks, depth first.
+//
+// Prompts whose conditions are not met are skipped. Prompts for variables
+// defined in Vars are not presented but their values are checked. A variable
+// is prompted for once, by the first prompt for it, even if more Templates
+// define a prompt for it. Prompts for variables overridden by Task Vars are
+// not presented for that Task. If NonInteractive is set, prompts are not
+// presented and default values are used instead.
+//
+// Values are stored in Data.Vars under names of Variables they prompt for.
+// Values are converted to prompt types and checked using Prompt.CheckValue.
+func (self *Executor) presentPrompts() (err error) {
+	var (
+		input     any
+		def       string
+		presented = make(map[string]bool)
+	)
+	for _, task := range self.Tasks {
+		if task.Metafile == nil {
+			continue
+		}
+		for _, prompt := range task.Metafile.Prompts {
+			if task.Vars.Exists(prompt.Variable) {
+				if err = prompt.CheckValue(task.Vars[prompt.Variable]); err != nil {
+					return fmt.Errorf("template '%s': %w", task.Metafile.Path, err)
+				}
+				continue
+			}
+			if presented[prompt.Variable] {
+				continue
+			}
+			if prompt.When != "" {
+				var ask bool
+				if ask, err = EvaluateCondition(prompt.When, self.Data); err != nil {
+					return fmt.Errorf("prompt '%s': %w", prompt.Variable, err)
+				}
+				if !ask {
+					continue
+				}
+			}
+			presented[prompt.Variable] = true
+			if self.Vars.Exists(prompt.Variable) {
+				if err = prompt.CheckValue(self.Data.Vars[prompt.Variable]); err != nil {
+					return
+				}
+				self.record(task.Metafile.Path, prompt.Variable, self.Data.Vars[prompt.Variable])
+				continue
+			}
+			if def, err = self.promptDefault(prompt); err != nil {
+				return fmt.Errorf("prompt '%s' default: %w", prompt.Variable, err)
+			}
+			if self.NonInteractive {
+				if def == "" {
+					if prompt.Optional {
+						continue
+					}
+					return fmt.Errorf("no value for required variable '%s' of template '%s'",
+						prompt.Variable, task.Metafile.Path)
+				}
+				input = def
+			} else if input, err = self.Prompter.Prompt(task.Metafile.Path, prompt, def); err != nil {
+				return err
+			}
+			if input, err = prompt.ConvertValue(input); err != nil {
+				return
+			}
+			if err = prompt.CheckValue(input); err != nil {
+				return
+			}
+			self.Data.Vars[prompt.Variable] = input
+			self.record(task.Metafile.Path, prompt.Variable, input)
+		}
+	}
+	return nil
+}
+
+// selectMembers implements MemberSelector. It selects optional members of
+// the Group at groupPath using a multiple choice prompt for a variable named
+// after groupPath, i.e. "apps/cliapp#full", with member Template paths as
+// choices and members selected by default as the default value.
+//
+// The prompt is answered like other prompts: from Vars if the variable is
+// defined there, with the default value if NonInteractive is set or there is
+// no Prompter, otherwise by Prompter. The selection is recorded.
+func (self *Executor) selectMembers(groupPath string, group *Group, optional GroupMembers) (selected GroupMembers, err error) {
+	var (
+		path, _, _ = strings.Cut(groupPath, "#")
+		prompt     = &Prompt{
+			Variable:    groupPath,
+			Description: group.Description,
+			Optional:    true,
+			Type:        PromptMultiChoice,
+		}
+		defaults []string
+		value    any
+	)
+	for _, member := range optional {
+		prompt.Choices = append(prompt.Choices, member.Template)
+		if member.Selected {
+			defaults = append(defaults, member.Template)
+		}
+	}
+	var def = strings.Join(defaults, ",")
+	if prompt.Description == "" {
+		prompt.Description = "Optional group members"
+	}
+	if v, exists := self.Vars[groupPath]; exists {
+		value = v
+	} else if self.NonInteractive || self.Prompter == nil {
+		value = def
+	} else if value, err = self.Prompter.Prompt(path, prompt, def); err != nil {
+		return nil, err
+	}
+	if value, err = prompt.ConvertValue(value); err != nil {
+		return
+	}
+	if err = prompt.CheckValue(value); err != nil {
+		return
+	}
+	self.selected[groupPath] = value
+	self.record(path, groupPath, value)
+	for _, name := range value.([]string) {
+		for _, member := range optional {
+			if member.Template == name {
+				selected = append(selected, member)
+			}
+		}
+	}
+	return
+}
+
+// record adds a prompt value to Record if it is set.
+func (self *Executor) record(templatePath, variable string, value any) {
+	if self.Record != nil {
+		self.Record.Add(templatePath, variable, value)
+	}
+}
+
+// promptDefault returns the default value for a prompt. It is the prompt
+// Default executed as a template with Data if set or a value derived from
+// standard variables for prompts of standard variables.
+func (self *Executor) promptDefault(prompt *Prompt) (def string, err error) {
+	if prompt.Default != "" {
+		return ExecuteTemplateString(prompt.Default, self.Data)
+	}
+	switch prompt.Variable {
+	case VarProjectName.String():
+		// Set default value to base of output dir in vars.
+		// if current dir, set from state.
+		// if again current dir, use a magic name.
+		if def = filepath.Base(self.Data.StringVar(VarOutputDir.String())); def != "" && def != "." {
+			return
+		}
+		if def = filepath.Base(self.OutputDir); def == "." {
+			def = "new_project"
+		}
+	case VarModulePath.String():
+		// Set default value to config.ModulePrefix + ProjectName.
+		if def = self.Data.StringVar(VarProjectName.String()); def == "" || def == "." {
+			if def = filepath.Base(self.OutputDir); def == "." {
+				def = "new_project"
+			}
+		}
+		def = filepath.Join(self.Config.Author.ModulePrefix, def)
+	case VarOutputDir.String():
+		def = self.OutputDir
+	}
+	return
+}
+
+// execute creates directories and executes template files of all Tasks into
+// Output. If MakeBackups is enabled and output is written to disk a backup is
+// made before writing and restored if an error occurs.
+func (self *Executor) execute(printer *Printer) (err error) {
+
+	if self.Manifest != nil {
+		if self.Manifest.TemplatePath == "" {
+			self.Manifest.TemplatePath = self.TemplatePath
+		}
+		if task := self.Tasks.Template(self.TemplatePath); task != nil {
+			self.Manifest.Version = task.Metafile.Version
+		}
+		self.Manifest.Created = time.Now()
+		self.Manifest.Vars = self.manifestVars()
+		self.Manifest.Files = nil
+	}
+
+	if _, disk := self.Output.(DiskFS); disk && self.MakeBackups {
+		var (
+			id          string
+			files, dirs = self.Tasks.Targets()
+		)
+		if self.Manifest != nil {
+			for _, file := range files {
+				files = append(files, ManifestBasePath(self.OutputDir, self.relTarget(file)))
+			}
+			files = append(files, ManifestPath(self.OutputDir))
+		}
+		if id, err = CreateBackup(self.OutputDir, files, dirs); err != nil {
+			return fmt.Errorf("create target dir backup: %w", err)
+		}
+		if self.Logger != nil {
+			printer.Printf("Created backup %s\n", id)
+		}
+		defer func() {
+			if err != nil {
+				if e := RestoreBackup(id); e != nil {
+					err = fmt.Errorf("restore backup failed after error '%w': %w", err, e)
+				}
+			}
+		}()
+	}
+
+	for _, exec := range self.Tasks {
+		// Create dirs.
+		for _, item := range exec.List {
+			if !item.IsDir {
+				continue
+			}
+			if err = self.Output.MkdirAll(item.Target, os.ModePerm); err != nil {
+				return fmt.Errorf("error creating target directory %s: %w", item.Target, err)
+			}
+		}
+
+		// Execute source templates.
+		var partials *template.Template
+		if partials, err = ParsePartials(self.Repository, exec.Partials, self.Data.FuncMap()); err != nil {
+			return fmt.Errorf("parse partials: %w", err)
+		}
+		for _, item := range exec.List {
+			if item.IsDir {
+				continue
+			}
+			var (
+				buf []byte
+				tt  *template.Template
+				out bytes.Buffer
+			)
+			if buf, err = self.Repository.ReadFile(item.Source); err != nil {
+				return fmt.Errorf("read template file '%s': %w", item.Source, err)
+			}
+			if tt, err = partials.Clone(); err != nil {
+				return fmt.Errorf("clone partials: %w", err)
+			}
+			if tt, err = tt.New(filepath.Base(item.Source)).Parse(string(buf)); err != nil {
+				return fmt.Errorf("parse template file: %w", err)
+			}
+			if self.Logger != nil {
+				printer.Printf("Template %s\n", tt.Name())
+				tmpl.PrintTemplate(tt)
+			}
+			if err = tt.Execute(&out, exec.Data(self.Data)); err != nil {
+				return fmt.Errorf("execute template '%s' into target '%s': %w", item.Source, item.Target, err)
+			}
+			if err = self.Output.MkdirAll(filepath.Dir(item.Target), os.ModePerm); err != nil {
+				return fmt.Errorf("create target file dir '%s': %w", filepath.Dir(item.Target), err)
+			}
+			var generated bool
+			if generated, err = self.write(printer, item.Target, item.Conflict, out.Bytes()); err != nil {
+				return fmt.Errorf("write target file '%s': %w", item.Target, err)
+			}
+			if self.Manifest != nil && generated {
+				if err = self.Manifest.AddFile(
+					self.Output, self.OutputDir, self.relTarget(item.Target), out.Bytes(),
+				); err != nil {
+					return fmt.Errorf("write manifest base file: %w", err)
+				}
+			}
+		}
+	}
+	if self.Manifest != nil {
+		if err = self.Manifest.Write(self.Output, self.OutputDir); err != nil {
+			return fmt.Errorf("write manifest: %w", err)
+		}
+	}
+	return nil
+}
+
+// relTarget returns target path relative to OutputDir in slash format.
+func (self *Executor) relTarget(target string) string {
+	var rel, err = filepath.Rel(self.OutputDir, target)
+	if err != nil {
+		return filepath.ToSlash(target)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// mergeBase returns the base copy of the file at target recorded with the
+// Previous Manifest or nil if there is none.
+func (self *Executor) mergeBase(target string) []byte {
+	if self.Previous == nil {
+		return nil
+	}
+	var base, err = self.Previous.ReadBase(self.Output, self.OutputDir, self.relTarget(target))
+	if err != nil {
+		return nil
+	}
+	return base
+}
+
+// write writes rendered Template output to the target file in Output
+// according to the conflict policy. An empty policy writes the file.
+//
+// It returns true if the target was generated, i.e. written with rendered
+// or merged with it, and false if it was skipped or rendered was written
+// elsewhere or appended to it.
+func (self *Executor) write(printer *Printer, target string, policy ConflictPolicy, rendered []byte) (generated bool, err error) {
+
+	if policy == "" || policy == ConflictOverwrite {
+		if err = self.Output.WriteFile(target, rendered, 0666); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	var current []byte
+	if current, err = self.Output.ReadFile(target); err != nil {
+		return
+	}
+
+	switch policy {
+	case ConflictSkip:
+		printer.Printf("Skipped existing file %s\n", target)
+		return false, nil
+	case ConflictAppend:
+		return false, self.Output.WriteFile(target, append(current, rendered...), 0666)
+	case ConflictNew:
+		printer.Printf("Wrote %s\n", target+SideFileExt)
+		return false, self.Output.WriteFile(target+SideFileExt, rendered, 0666)
+	case ConflictMerge:
+		var merged, conflicts = Merge3(self.mergeBase(target), current, rendered)
+		if conflicts > 0 {
+			self.Conflicted = append(self.Conflicted, target)
+			printer.Printf("Merged %s with %d conflicts\n", target, conflicts)
+		}
+		if err = self.Output.WriteFile(target, merged, 0666); err != nil {
+			return false, err
+		}
+		return true, nil
+	case ConflictPrompt:
+		if self.Resolver == nil {
+			return false, fmt.Errorf("target file already exists: %s", target)
+		}
+		if policy, err = self.Resolver.ResolveConflict(target, current, rendered); err != nil {
+			return false, fmt.Errorf("resolve conflict: %w", err)
+		}
+		if policy == ConflictPrompt || policy == ConflictFail {
+			return false, fmt.Errorf("target file already exists: %s", target)
+		}
+		return self.write(printer, target, policy, rendered)
+	}
+
+	return false, fmt.Errorf("invalid conflict policy '%s'", policy)
+}
+
+// manifestVars returns variables to record in a Manifest. Standard variables
+// specific to the machine or the execution, such as OutputDir and author
+// details, are omitted so the Manifest can be shared. Author details are
+// kept if given in Vars or by a prompt.
+func (self *Executor) manifestVars() (out Variables) {
+	var prompted = make(map[string]bool)
+	for _, task := range self.Tasks {
+		if task.Metafile == nil {
+			continue
+		}
+		for _, prompt := range task.Metafile.Prompts {
+			prompted[prompt.Variable] = true
+		}
+	}
+	out = make(Variables, len(self.Data.Vars))
+	for k, v := range self.Data.Vars {
+		switch k {
+		case VarTemplatePath.String(), VarOutputDir.String(),
+			VarWorkingDir.String(), VarEditTarget.String():
+			continue
+		case VarAuthorName.String(), VarAuthorEmail.String(), VarAuthorHomepage.String():
+			if _, given := self.Vars[k]; !given && !prompted[k] {
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return
+}
+
+// printer returns a *Printer that prints to Logger or discards output if
+// Logger is nil.
+func (self *Executor) printer() *Printer {
+	if self.Logger == nil {
+		return NewPrinter(io.Discard)
+	}
+	return NewPrinter(self.Logger)
+}
@@ -0,0 +1,178 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// testRepository is a repository with an "app" template that has prompts,
+// a placeholder path and an action.
+var testRepository = fstest.MapFS{
+	"app/boil.json": {Data: []byte(`{
+		"name": "app",
+		"version": "1.0.0",
+		"files": [
+			{"path": "main.go"},
+			{"path": "$Name|snake/readme.md"}
+		],
+		"prompts": [
+			{"variable": "Name", "description": "Name"},
+			{"variable": "Port", "type": "int", "default": "8080"}
+		],
+		"actions": {
+			"preExecute": [
+				{"name": "setup", "program": "setup", "arguments": ["{{.Vars.Name | kebab}}"]}
+			]
+		}
+	}`)},
+	"app/main.go":               {Data: []byte(`package {{.Vars.Name | snake}} // port {{.Vars.Port}}`)},
+	"app/$Name|snake/readme.md": {Data: []byte(`# {{.Vars.Name | title}}`)},
+}
+
+// newTestExecutor returns an Executor for fsys that writes to a MemoryFS,
+// answers prompts from answers and records action arguments into args.
+func newTestExecutor(fsys fstest.MapFS, answers Variables, args *[]string) (*Executor, *MemoryFS) {
+	var (
+		output = NewMemoryFS()
+		exec   = NewExecutor(&Config{Author: Author{Name: "test"}}, NewFSRepository(fsys))
+	)
+	exec.Output = output
+	exec.Resolver = nil
+	exec.Prompter = PrompterFunc(func(templatePath string, prompt *Prompt, def string) (any, error) {
+		if v, ok := answers[prompt.Variable]; ok {
+			return v, nil
+		}
+		return def, nil
+	})
+	exec.Runner = ActionRunnerFunc(func(action *Action, data *Data) error {
+		for _, arg := range action.Arguments {
+			var s, err = ExecuteTemplateString(arg, data)
+			if err != nil {
+				return err
+			}
+			*args = append(*args, s)
+		}
+		return nil
+	})
+	return exec, output
+}
+
+func TestExecutor(t *testing.T) {
+
+	var (
+		args         []string
+		exec, output = newTestExecutor(testRepository, Variables{"Name": "MyApp"}, &args)
+		out          = filepath.Join(string(filepath.Separator), "out")
+	)
+	if err := exec.Execute("app", out); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		filepath.Join(out, "main.go"):             "package my_app // port 8080",
+		filepath.Join(out, "my_app", "readme.md"): "# My App",
+	} {
+		if data, err := output.ReadFile(name); err != nil || string(data) != want {
+			t.Errorf("%s: got %q, %v, want %q", name, data, err, want)
+		}
+	}
+	if len(output.Files()) != 2 {
+		t.Errorf("unexpected output files: %v", output.Files())
+	}
+	if !reflect.DeepEqual(args, []string{"my-app"}) {
+		t.Errorf("action arguments: got %v", args)
+	}
+	if exec.Data.Vars["Port"] != 8080 {
+		t.Errorf("prompt value not converted: %#v", exec.Data.Vars["Port"])
+	}
+
+	// Existing output fails without a conflict policy.
+	if err := exec.Execute("app", out); err == nil {
+		t.Fatal("expected conflict error")
+	}
+	exec.Overwrite = true
+	if err := exec.Execute("app", out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecutorManifest(t *testing.T) {
+
+	var (
+		args         []string
+		exec, output = newTestExecutor(testRepository, Variables{"Name": "MyApp"}, &args)
+		out          = filepath.Join(string(filepath.Separator), "out")
+		readme       = filepath.Join(out, "my_app", "readme.md")
+	)
+	if err := output.MkdirAll(filepath.Dir(readme), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := output.WriteFile(readme, []byte("user readme"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exec.Manifest = new(Manifest)
+	exec.OnConflict = ConflictSkip
+	if err := exec.Execute("app", out); err != nil {
+		t.Fatal(err)
+	}
+
+	var manifest, err = ReadManifest(output, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, file := range manifest.Files {
+		files = append(files, file.Path)
+	}
+	if !reflect.DeepEqual(files, []string{"main.go"}) {
+		t.Errorf("manifest files: got %v, want only generated files", files)
+	}
+	if _, err = manifest.ReadBase(output, out, "my_app/readme.md"); err == nil {
+		t.Error("base copy recorded for a skipped file")
+	}
+
+	for _, name := range []string{"Name", "Port"} {
+		if _, exists := manifest.Vars[name]; !exists {
+			t.Errorf("manifest vars: missing %s", name)
+		}
+	}
+	for _, name := range []string{"OutputDir", "TemplatePath", "AuthorName", "AuthorEmail", "AuthorHomepage"} {
+		if _, exists := manifest.Vars[name]; exists {
+			t.Errorf("manifest vars: unexpected machine specific variable %s", name)
+		}
+	}
+
+	// The version of the executed Template is recorded, not the version of a
+	// Template it requires or of a Group member, whose Tasks come first.
+	var fsys = fstest.MapFS{
+		"app/lib/boil.json": {Data: []byte(`{"version": "0.1.0", "files": [{"path": "lib.go"}]}`)},
+		"app/lib/lib.go":    {},
+		"app/boil.json": {Data: []byte(`{
+			"version": "1.0.0",
+			"requires": ["app/lib"],
+			"files": [{"path": "app.go"}],
+			"groups": [{"name": "all", "templates": ["lib"]}]
+		}`)},
+		"app/app.go": {},
+	}
+	for _, path := range []string{"app", "app#all"} {
+		exec, output = newTestExecutor(fsys, nil, &args)
+		exec.Manifest = new(Manifest)
+		if err = exec.Execute(path, out); err != nil {
+			t.Fatal(err)
+		}
+		if manifest, err = ReadManifest(output, out); err != nil {
+			t.Fatal(err)
+		}
+		if manifest.Version != "1.0.0" {
+			t.Errorf("%s: manifest version: got %q, want 1.0.0", path, manifest.Version)
+		}
+	}
+}
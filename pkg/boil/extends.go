@@ -0,0 +1,203 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Removals lists entries inherited from an extended Template to remove from
+// a Metafile, by name. See Metafile.Extends.
+type Removals struct {
+	// Files are paths of files to remove.
+	Files []string `json:"files,omitempty"`
+	// Directories are paths of directories to remove.
+	Directories []string `json:"directories,omitempty"`
+	// Prompts are variables of prompts to remove.
+	Prompts []string `json:"prompts,omitempty"`
+	// Actions are names of actions to remove from all action stages.
+	Actions []string `json:"actions,omitempty"`
+	// Groups are names of groups to remove.
+	Groups []string `json:"groups,omitempty"`
+	// Conflicts are patterns of conflict rules to remove.
+	Conflicts []string `json:"conflicts,omitempty"`
+	// Requires are paths of required templates to remove.
+	Requires []string `json:"requires,omitempty"`
+}
+
+// ResolveExtends returns a Metafile that is meta merged with the Metafiles
+// of Templates it extends from repo, recursively. If meta does not extend a
+// Template it is returned as is.
+//
+// The merged Metafile has the Path of meta. Entries inherited from a parent
+// are first removed as specified by meta.Remove, then entries defined by meta
+// override inherited entries with the same name in place and other entries
+// are appended. Entries are named by file and directory paths, prompt
+// variables, action and group names, conflict rule patterns and required
+// template paths. Conflict rules of meta precede inherited rules as the first
+// matching rule applies. Other fields of meta override inherited fields if
+// they are not empty.
+//
+// Inherited files, directories and groups retain the path of the Template
+// that defines them in their Template field. A Metafile returned by
+// ResolveExtends is returned as is if resolved again.
+func ResolveExtends(repo Repository, meta *Metafile) (*Metafile, error) {
+	return resolveExtends(repo, meta, []string{filepath.Clean(meta.Path)})
+}
+
+// resolveExtends implements ResolveExtends. chain holds the clean paths of
+// Templates being resolved and is used to detect cycles.
+func resolveExtends(repo Repository, meta *Metafile, chain []string) (out *Metafile, err error) {
+
+	if meta.Extends == "" || len(meta.extended) > 0 {
+		return meta, nil
+	}
+
+	var extends = filepath.Clean(meta.Extends)
+	for _, path := range chain {
+		if path == extends {
+			return nil, fmt.Errorf("extends cycle: %s -> %s", strings.Join(chain, " -> "), extends)
+		}
+	}
+
+	var parent *Metafile
+	if parent, err = repo.OpenMeta(extends); err != nil {
+		return nil, fmt.Errorf("open extended template %s of %s: %w", extends, meta.Path, err)
+	}
+	if parent, err = resolveExtends(repo, parent, append(chain, extends)); err != nil {
+		return nil, err
+	}
+
+	return mergeMetafile(parent, meta), nil
+}
+
+// mergeMetafile returns a new Metafile that is child merged over parent.
+// See ResolveExtends.
+func mergeMetafile(parent, child *Metafile) *Metafile {
+
+	var (
+		out    = *child
+		remove = child.Remove
+	)
+	if remove == nil {
+		remove = new(Removals)
+	}
+	out.Remove = nil
+	out.extended = append(append([]string{}, parent.extended...), parent.Path)
+
+	if out.Description == "" {
+		out.Description = parent.Description
+	}
+	if out.Author == nil {
+		out.Author = parent.Author
+	}
+	if out.Version == "" {
+		out.Version = parent.Version
+	}
+	if out.URL == "" {
+		out.URL = parent.URL
+	}
+
+	out.Files = mergeEntries(parent.Path, parent.Files, child.Files, remove.Files)
+	out.Directories = mergeEntries(parent.Path, parent.Directories, child.Directories, remove.Directories)
+
+	out.Prompts = mergeNamed(parent.Prompts, child.Prompts, remove.Prompts,
+		func(prompt *Prompt) string { return prompt.Variable },
+	)
+
+	var actionName = func(action *Action) string { return action.Name }
+	out.Actions.PreParse = mergeNamed(parent.Actions.PreParse, child.Actions.PreParse, remove.Actions, actionName)
+	out.Actions.PreExecute = mergeNamed(parent.Actions.PreExecute, child.Actions.PreExecute, remove.Actions, actionName)
+	out.Actions.PostExecute = mergeNamed(parent.Actions.PostExecute, child.Actions.PostExecute, remove.Actions, actionName)
+
+	var groups []*Group
+	for _, group := range parent.Groups {
+		var inherited = *group
+		if inherited.Template == "" {
+			inherited.Template = parent.Path
+		}
+		groups = append(groups, &inherited)
+	}
+	out.Groups = mergeNamed(groups, child.Groups, remove.Groups,
+		func(group *Group) string { return group.Name },
+	)
+
+	out.Requires = mergeNamed(parent.Requires, child.Requires, remove.Requires, requirementPath)
+
+	// Rules of the child come first so they take precedence.
+	var pattern = func(rule *ConflictRule) string { return rule.Pattern }
+	out.Conflicts = append(ConflictRules{}, child.Conflicts...)
+	for _, rule := range parent.Conflicts {
+		if !containsName(remove.Conflicts, rule.Pattern) && indexNamed(child.Conflicts, rule.Pattern, pattern) < 0 {
+			out.Conflicts = append(out.Conflicts, rule)
+		}
+	}
+
+	return &out
+}
+
+// mergeEntries merges child entries over parent entries defined by the
+// Template at parentPath. See mergeNamed.
+func mergeEntries(parentPath string, parent, child Entries, remove []string) Entries {
+	var inherited Entries
+	for _, entry := range parent {
+		var e = *entry
+		if e.Template == "" {
+			e.Template = parentPath
+		}
+		inherited = append(inherited, &e)
+	}
+	return mergeNamed(inherited, child, remove,
+		func(entry *Entry) string { return entry.Path },
+	)
+}
+
+// mergeNamed returns parent items without items named in remove, with items
+// of child replacing parent items of the same name in place, followed by
+// other child items. Items with an empty name are never replaced or removed.
+func mergeNamed[T any](parent, child []T, remove []string, name func(T) string) (out []T) {
+	var replaced = make(map[int]bool)
+	for _, item := range parent {
+		var n = name(item)
+		if n != "" && containsName(remove, n) {
+			continue
+		}
+		if n != "" {
+			if i := indexNamed(child, n, name); i >= 0 {
+				replaced[i] = true
+				item = child[i]
+			}
+		}
+		out = append(out, item)
+	}
+	for i, item := range child {
+		if !replaced[i] {
+			out = append(out, item)
+		}
+	}
+	return
+}
+
+// indexNamed returns the index of the first item in items named n or -1.
+func indexNamed[T any](items []T, n string, name func(T) string) int {
+	for i, item := range items {
+		if name(item) == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsName returns true if names contains name.
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
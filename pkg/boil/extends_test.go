@@ -0,0 +1,77 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolveExtends(t *testing.T) {
+
+	var repo = NewFSRepository(fstest.MapFS{
+		"base/boil.json": {Data: []byte(`{
+			"description": "base",
+			"files": [{"path": "a.go"}, {"path": "b.go"}],
+			"prompts": [{"variable": "Name"}, {"variable": "Port", "default": "80"}]
+		}`)},
+		"app/boil.json": {Data: []byte(`{
+			"extends": "./base/",
+			"remove": {"files": ["b.go"]},
+			"files": [{"path": "c.go"}],
+			"prompts": [{"variable": "Port", "default": "8080"}]
+		}`)},
+	})
+
+	var meta, err = repo.OpenMeta("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta, err = ResolveExtends(repo, meta); err != nil {
+		t.Fatal(err)
+	}
+	if meta.Description != "base" {
+		t.Errorf("description: got %q", meta.Description)
+	}
+	var files []string
+	for _, file := range meta.Files {
+		files = append(files, file.Source(meta.Path))
+	}
+	if want := []string{filepath.Join("base", "a.go"), filepath.Join("app", "c.go")}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files: got %v, want %v", files, want)
+	}
+	if len(meta.Prompts) != 2 || meta.Prompts[1].Default != "8080" {
+		t.Errorf("prompts: got %v", meta.Prompts)
+	}
+	if meta.Remove != nil {
+		t.Error("remove not cleared")
+	}
+
+	// Resolving again returns the resolved Metafile as is.
+	var again *Metafile
+	if again, err = ResolveExtends(repo, meta); err != nil || again != meta {
+		t.Errorf("resolved again: %v", err)
+	}
+}
+
+func TestResolveExtendsCycle(t *testing.T) {
+
+	var repo = NewFSRepository(fstest.MapFS{
+		"a/boil.json": {Data: []byte(`{"extends": "./b"}`)},
+		"b/boil.json": {Data: []byte(`{"extends": "c/"}`)},
+		"c/boil.json": {Data: []byte(`{"extends": "a/../a"}`)},
+	})
+
+	var meta, err = repo.OpenMeta("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ResolveExtends(repo, meta); err == nil || !strings.Contains(err.Error(), "extends cycle: a -> b -> c -> a") {
+		t.Fatalf("expected extends cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrReadOnlyRepository is returned by write operations of a read-only
+// Repository.
+var ErrReadOnlyRepository = errors.New("repository is read-only")
+
+// NewFSRepository returns a new read-only *FSRepository that serves templates
+// from fsys, i.e. an embed.FS. The root of fsys is the repository root. Use
+// fs.Sub to serve a repository from a subdirectory of fsys.
+func NewFSRepository(fsys fs.FS) *FSRepository {
+	return &FSRepository{fsys: fsys, location: "fs"}
+}
+
+// FSRepository is a read-only Repository backed by an fs.FS.
+//
+// Repository paths are converted to slash separated fs.FS paths and paths
+// given to WalkDir callbacks are converted back to OS paths.
+type FSRepository struct {
+	// fsys is the filesystem templates are served from.
+	fsys fs.FS
+	// location is the repository location.
+	location string
+	// strict if true rejects Metafiles with unknown fields.
+	strict bool
+}
+
+// Location implements Repository.Location.
+// It returns the location given to NewFSRepository.
+func (self *FSRepository) Location() string { return self.location }
+
+// SetStrict implements StrictRepository.SetStrict.
+func (self *FSRepository) SetStrict(strict bool) { self.strict = strict }
+
+// LoadMetamap implements Repository.LoadMetamap.
+func (self *FSRepository) LoadMetamap() (metamap Metamap, err error) {
+	metamap = make(Metamap)
+	if err = fs.WalkDir(self.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("walk error: %w", err)
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		var meta *Metafile
+		if meta, err = self.OpenMeta(filepath.FromSlash(name)); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		metamap.add(meta.Path, meta)
+		return nil
+	}); err != nil {
+		err = fmt.Errorf("load metamap from filesystem: %w", err)
+	}
+	return
+}
+
+// HasMeta implements Repository.HasMeta.
+func (self *FSRepository) HasMeta(path string) (exists bool, err error) {
+	var format MetafileFormat
+	if format, err = self.findMeta(path); err != nil {
+		return false, err
+	}
+	return format != "", nil
+}
+
+// OpenMeta implements Repository.OpenMeta.
+func (self *FSRepository) OpenMeta(path string) (meta *Metafile, err error) {
+	var format MetafileFormat
+	if format, err = self.findMeta(path); err != nil {
+		return nil, fmt.Errorf("openmeta: %w", err)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("openmeta: %w", &fs.PathError{
+			Op:   "open",
+			Path: fsPath(filepath.Join(path, MetafileName)),
+			Err:  fs.ErrNotExist,
+		})
+	}
+	var (
+		filename = filepath.Join(path, format.Filename())
+		data     []byte
+	)
+	if data, err = self.ReadFile(filename); err != nil {
+		return nil, fmt.Errorf("openmeta: %w", err)
+	}
+	if meta, err = UnmarshalMetafile(data, format, self.strict); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	meta.Path = path
+	return
+}
+
+// findMeta returns the format of the Metafile in the directory at path or an
+// empty format if the directory does not contain a Metafile.
+func (self *FSRepository) findMeta(path string) (format MetafileFormat, err error) {
+	var exists bool
+	for _, format = range MetafileFormats {
+		if exists, err = self.Exists(filepath.Join(path, format.Filename())); err != nil {
+			return "", err
+		}
+		if exists {
+			return format, nil
+		}
+	}
+	return "", nil
+}
+
+// SaveMeta implements Repository.SaveMeta.
+// It always returns ErrReadOnlyRepository.
+func (self *FSRepository) SaveMeta(meta *Metafile) error { return ErrReadOnlyRepository }
+
+// Exists implements Repository.Exists.
+func (self *FSRepository) Exists(path string) (exists bool, err error) {
+	if _, err = fs.Stat(self.fsys, fsPath(path)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// ReadFile implements Repository.ReadFile.
+func (self *FSRepository) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(self.fsys, fsPath(name))
+}
+
+// WriteFile implements Repository.WriteFile.
+// It always returns ErrReadOnlyRepository.
+func (self *FSRepository) WriteFile(name string, data []byte) error {
+	return ErrReadOnlyRepository
+}
+
+// Mkdir implements Repository.Mkdir.
+// It always returns ErrReadOnlyRepository.
+func (self *FSRepository) Mkdir(path string) error { return ErrReadOnlyRepository }
+
+// Remove implements Repository.Remove.
+// It always returns ErrReadOnlyRepository.
+func (self *FSRepository) Remove(path string) error { return ErrReadOnlyRepository }
+
+// WalkDir implements Repository.WalkDir.
+func (self *FSRepository) WalkDir(root string, f fs.WalkDirFunc) error {
+	return fs.WalkDir(self.fsys, fsPath(root), func(name string, d fs.DirEntry, err error) error {
+		return f(filepath.FromSlash(name), d, err)
+	})
+}
+
+// fsPath converts a repository path to a path valid in an fs.FS.
+func fsPath(name string) string {
+	name = path.Clean(filepath.ToSlash(name))
+	if name = strings.TrimPrefix(name, "/"); name == "" {
+		return "."
+	}
+	return name
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+// testFSRepository is a repository with Metafiles in all formats, a nested
+// Template and a directory without a Metafile.
+var testFSRepository = fstest.MapFS{
+	"app/boil.json": {Data: []byte(`{
+		"name": "app",
+		"groups": [{"name": "all", "templates": ["sub"]}]
+	}`)},
+	"app/main.go":       {},
+	"app/sub/boil.yaml": {Data: []byte("name: sub\n")},
+	"lib/boil.toml":     {Data: []byte("name = \"lib\"\n")},
+	"lib/lib.go":        {Data: []byte("package lib")},
+	"plain/readme.md":   {},
+	"strict/boil.json":  {Data: []byte(`{"name": "strict", "unknown": true}`)},
+}
+
+func TestFSRepositoryMeta(t *testing.T) {
+
+	var repo = NewFSRepository(testFSRepository)
+	if repo.Location() != "fs" {
+		t.Errorf("location: got %s", repo.Location())
+	}
+
+	var metamap, err = repo.LoadMetamap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for key := range metamap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var want = []string{"app", "app#all", filepath.Join("app", "sub"), "lib", "strict"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("metamap: got %v, want %v", keys, want)
+	}
+	if metamap["app#all"] != metamap["app"] {
+		t.Error("metamap: group key does not address its Template")
+	}
+
+	for _, test := range []struct {
+		path   string
+		name   string
+		format MetafileFormat
+	}{
+		{"app", "app", MetafileJSON},
+		{filepath.Join("app", "sub"), "sub", MetafileYAML},
+		{"lib", "lib", MetafileTOML},
+	} {
+		var meta *Metafile
+		if meta, err = repo.OpenMeta(test.path); err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		if meta.Name != test.name || meta.Format != test.format || meta.Path != test.path {
+			t.Errorf("%s: got name %q, format %q, path %q", test.path, meta.Name, meta.Format, meta.Path)
+		}
+		if exists, err := repo.HasMeta(test.path); err != nil || !exists {
+			t.Errorf("%s: has meta: got %t, %v", test.path, exists, err)
+		}
+	}
+
+	for _, path := range []string{"plain", "missing"} {
+		if _, err = repo.OpenMeta(path); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: expected not exist error, got %v", path, err)
+		}
+		if exists, err := repo.HasMeta(path); err != nil || exists {
+			t.Errorf("%s: has meta: got %t, %v", path, exists, err)
+		}
+	}
+
+	// Unknown fields are rejected in strict mode.
+	repo.SetStrict(true)
+	if _, err = repo.OpenMeta("strict"); err == nil {
+		t.Error("strict: expected unknown field error")
+	}
+	if _, err = repo.LoadMetamap(); err == nil {
+		t.Error("strict metamap: expected unknown field error")
+	}
+}
+
+func TestFSRepositoryFiles(t *testing.T) {
+
+	var repo = NewFSRepository(testFSRepository)
+
+	for path, want := range map[string]bool{
+		"lib":                              true,
+		filepath.Join("lib", "lib.go"):     true,
+		filepath.Join("app", "..", "lib"):  true,
+		string(filepath.Separator) + "lib": true,
+		filepath.Join("lib", "missing.go"): false,
+		"":                                 true,
+	} {
+		if exists, err := repo.Exists(path); err != nil || exists != want {
+			t.Errorf("exists %q: got %t, %v, want %t", path, exists, err, want)
+		}
+	}
+	if data, err := repo.ReadFile(filepath.Join("app", "..", "lib", "lib.go")); err != nil || string(data) != "package lib" {
+		t.Errorf("read file: got %q, %v", data, err)
+	}
+
+	var paths []string
+	if err := repo.WalkDir("app", func(path string, d fs.DirEntry, err error) error {
+		paths = append(paths, path)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var want = []string{
+		"app",
+		filepath.Join("app", "boil.json"),
+		filepath.Join("app", "main.go"),
+		filepath.Join("app", "sub"),
+		filepath.Join("app", "sub", "boil.yaml"),
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("walk: got %v, want %v", paths, want)
+	}
+
+	for name, err := range map[string]error{
+		"write":  repo.WriteFile("lib/new.go", nil),
+		"mkdir":  repo.Mkdir("new"),
+		"remove": repo.Remove("lib"),
+		"save":   repo.SaveMeta(&Metafile{Path: "lib"}),
+	} {
+		if !errors.Is(err, ErrReadOnlyRepository) {
+			t.Errorf("%s: got %v, want read-only error", name, err)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FuncMap returns the boil template function library. It is available to
+// Template files, action definitions, prompt defaults and entry conditions,
+// along with functions of the Bast of the execution Data, see Data.FuncMap.
+//
+// Functions that take a single string and return a string are also path
+// filters, see PathFilters.
+//
+//	camel, pascal, snake,   Case conversion of words: fooBar, FooBar,
+//	kebab, screaming, title foo_bar, foo-bar, FOO_BAR and Foo Bar.
+//	upper, lower            Upper or lower case.
+//	plural, singular        English plural or singular form of the last word.
+//	goIdent                 A valid Go identifier made from a string.
+//	exported, unexported    An exported or unexported Go identifier.
+//	now                     The current time.
+//	date LAYOUT TIME        TIME formatted using a Go time LAYOUT.
+//	uuid                    A random version 4 UUID.
+//	indent N S              S with each line indented by N spaces.
+//	nindent N S             Like indent, preceded by a new line.
+//	default DEF V           DEF if V is empty, otherwise V.
+//	coalesce V...           The first non empty V or nil.
+//	join SEP LIST           Elements of LIST joined with SEP.
+//	split SEP S             S split into a list at each SEP.
+//	toJson V, toYaml V      V encoded as JSON or YAML.
+func FuncMap() template.FuncMap {
+	var funcs = template.FuncMap{
+		"now":      time.Now,
+		"date":     formatDate,
+		"uuid":     newUUID,
+		"indent":   indent,
+		"nindent":  nindent,
+		"default":  defaultValue,
+		"coalesce": coalesce,
+		"join":     join,
+		"split":    split,
+		"toJson":   toJSON,
+		"toYaml":   toYAML,
+	}
+	for name, f := range PathFilters {
+		funcs[name] = f
+	}
+	return funcs
+}
+
+// formatDate returns t formatted using layout.
+func formatDate(layout string, t time.Time) string {
+	return t.Format(layout)
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate uuid: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// indent returns s with each non-empty line prefixed with n spaces.
+func indent(n int, s string) string {
+	var (
+		pad   = strings.Repeat(" ", n)
+		lines = strings.Split(s, "\n")
+	)
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// nindent returns s indented by n spaces preceded by a new line.
+func nindent(n int, s string) string {
+	return "\n" + indent(n, s)
+}
+
+// defaultValue returns def if v is empty, otherwise v.
+func defaultValue(def, v any) any {
+	if isEmpty(v) {
+		return def
+	}
+	return v
+}
+
+// coalesce returns the first value in values that is not empty or nil.
+func coalesce(values ...any) any {
+	for _, v := range values {
+		if !isEmpty(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+// isEmpty returns true if v is nil, a zero value or an empty string, slice
+// or map.
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+	var rv = reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	}
+	return rv.IsZero()
+}
+
+// join returns elements of list joined with sep. List may be a slice of any
+// type or a string which is returned as is.
+func join(sep string, list any) (string, error) {
+	if s, ok := list.(string); ok {
+		return s, nil
+	}
+	var rv = reflect.ValueOf(list)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: %T is not a list", list)
+	}
+	var items = make([]string, rv.Len())
+	for i := range items {
+		items[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return strings.Join(items, sep), nil
+}
+
+// split returns s split at each sep.
+func split(sep, s string) []string {
+	return strings.Split(s, sep)
+}
+
+// toJSON returns v encoded as JSON.
+func toJSON(v any) (string, error) {
+	var data, err = json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toJson: %w", err)
+	}
+	return string(data), nil
+}
+
+// toYAML returns v encoded as YAML without the trailing new line.
+func toYAML(v any) (string, error) {
+	var data, err = yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toYaml: %w", err)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// goIdent returns s as a valid Go identifier. Characters that are not
+// letters or digits separate words which are joined with following words
+// capitalized, a "_" is prepended if s starts with a digit and appended if
+// s is a Go keyword. An empty s returns "_".
+func goIdent(s string) string {
+	var b strings.Builder
+	for i, word := range splitWords(s) {
+		if i > 0 {
+			word = capitalize(word)
+		}
+		b.WriteString(word)
+	}
+	var ident = b.String()
+	switch {
+	case ident == "":
+		return "_"
+	case unicode.IsDigit([]rune(ident)[0]):
+		ident = "_" + ident
+	case token.IsKeyword(ident):
+		ident += "_"
+	}
+	return ident
+}
+
+// exported returns s as an exported Go identifier, see goIdent.
+func exported(s string) string {
+	var ident = goIdent(s)
+	if strings.HasPrefix(ident, "_") {
+		return "X" + ident
+	}
+	return capitalize(strings.TrimSuffix(ident, "_"))
+}
+
+// unexported returns s as an unexported Go identifier, see goIdent. A
+// leading initialism is lower cased as a whole, so "HTTPServer" becomes
+// "httpServer", "ID" becomes "id" and "URLsByID" becomes "urlsByID".
+func unexported(s string) string {
+	var runes = []rune(goIdent(s))
+	var n = 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	// Keep the last upper case letter of an initialism that starts a word.
+	if n > 1 && n < len(runes) && unicode.IsLower(runes[n]) && !isPluralSuffix(runes, n) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	var ident = string(runes)
+	if token.IsKeyword(ident) {
+		ident += "_"
+	}
+	return ident
+}
@@ -0,0 +1,34 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import "testing"
+
+func TestGoIdent(t *testing.T) {
+	for _, test := range []struct {
+		in, ident, exported, unexported string
+	}{
+		{"", "_", "X_", "_"},
+		{"my-app", "myApp", "MyApp", "myApp"},
+		{"user id", "userId", "UserId", "userId"},
+		{"type", "type_", "Type", "type_"},
+		{"2fa", "_2fa", "X_2fa", "_2fa"},
+		{"HTTPServer", "HTTPServer", "HTTPServer", "httpServer"},
+		{"ID", "ID", "ID", "id"},
+		{"URLs", "URLs", "URLs", "urls"},
+		{"URLsByID", "URLsByID", "URLsByID", "urlsByID"},
+		{"ASet", "ASet", "ASet", "aSet"},
+	} {
+		if out := goIdent(test.in); out != test.ident {
+			t.Errorf("goIdent %q: got %q, want %q", test.in, out, test.ident)
+		}
+		if out := exported(test.in); out != test.exported {
+			t.Errorf("exported %q: got %q, want %q", test.in, out, test.exported)
+		}
+		if out := unexported(test.in); out != test.unexported {
+			t.Errorf("unexported %q: got %q, want %q", test.in, out, test.unexported)
+		}
+	}
+}
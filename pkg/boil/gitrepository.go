@@ -0,0 +1,165 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitPrefix is the prefix that marks a repository path as a git repository
+// url, i.e. "git+https://example.com/templates.git".
+const GitPrefix = "git+"
+
+// IsGitRepositoryPath returns true if path addresses a git repository.
+//
+// A path addresses a git repository if it has a GitPrefix, i.e.
+// "git+https://host/templates.git" or "git+file:///srv/templates.git", or if
+// it is an scp-like ssh address such as "git@host:org/templates.git".
+func IsGitRepositoryPath(path string) bool {
+	return strings.HasPrefix(path, GitPrefix) || strings.HasPrefix(path, "git@")
+}
+
+// ParseGitRepositoryPath parses a git repository path and returns the url
+// to clone and the optional ref to check out.
+//
+// The ref is given as a "@" suffix to the repository path, i.e.
+// "git@host:org/templates.git@v1.2" addresses tag or branch "v1.2". A ref may
+// contain "/", i.e. "git+https://host/templates.git@feature/x". The path is
+// split at the last "@" that follows the host so a user name in the url is
+// not mistaken for a ref. GitPrefix is removed from the returned url.
+func ParseGitRepositoryPath(path string) (url, ref string) {
+	url = strings.TrimPrefix(path, GitPrefix)
+	var start int
+	if i := strings.Index(url, "://"); i >= 0 {
+		// Url, the path starts at the first "/" after the host.
+		if start = strings.Index(url[i+3:], "/"); start < 0 {
+			return
+		}
+		start += i + 3
+	} else if start = strings.Index(url, ":"); start < 0 {
+		// Scp-like address, the path starts after the first ":".
+		return
+	}
+	if i := strings.LastIndex(url[start:], "@"); i >= 0 {
+		url, ref = url[:start+i], url[start+i+1:]
+	}
+	return
+}
+
+// GitRepository is a Repository backed by a remote git repository.
+//
+// The remote is cloned into a local cache directory and fetched on each open
+// after which the requested ref or the remote default branch is checked out.
+// Each url and ref pair has its own cache directory so that repositories of
+// the same url with different refs do not share a checkout. If fetching
+// fails, i.e. when offline, the cached checkout is used as is.
+//
+// All Repository operations are performed on the local checkout by the
+// embedded DiskRepository.
+type GitRepository struct {
+	*DiskRepository
+	// url is the remote url.
+	url string
+	// ref is the checked out ref, empty for the default branch.
+	ref string
+	// stale is the error that prevented fetching the remote, if any.
+	stale error
+}
+
+// OpenGitRepository clones or fetches the git repository addressed by path
+// into the default git cache directory, checks out the ref specified in path
+// and returns it or an error. See ParseGitRepositoryPath for path format.
+//
+// If a cached checkout exists and fetching fails a warning is printed to
+// stderr and the cached checkout is used, see GitRepository.Stale.
+//
+// It requires git to be installed and available on PATH.
+func OpenGitRepository(path string) (repo *GitRepository, err error) {
+
+	repo = new(GitRepository)
+	if repo.url, repo.ref = ParseGitRepositoryPath(path); repo.url == "" {
+		return nil, fmt.Errorf("invalid git repository path: %s", path)
+	}
+
+	var dir = filepath.Join(DefaultGitCacheDir(), gitCacheName(repo.url, repo.ref))
+	if _, err = os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("stat git cache: %w", err)
+		}
+		if err = os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+			return nil, fmt.Errorf("create git cache dir: %w", err)
+		}
+		if err = git("", "clone", "--quiet", "--no-checkout", repo.url, dir); err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("clone %s: %w", repo.url, err)
+		}
+	} else if err = git(dir, "fetch", "--quiet", "--prune", "--tags", "--force", "origin"); err != nil {
+		repo.stale = fmt.Errorf("fetch %s: %w", repo.url, err)
+		fmt.Fprintf(os.Stderr, "warning: using cached checkout of %s: %v\n", repo.url, repo.stale)
+		repo.DiskRepository = NewDiskRepository(dir)
+		return repo, nil
+	}
+
+	// Prefer remote tracking branches so that branch refs follow the remote,
+	// fall back to tags and commits.
+	var target = "origin/HEAD"
+	if repo.ref != "" {
+		if target = "origin/" + repo.ref; git(dir, "rev-parse", "--verify", "--quiet", target) != nil {
+			target = repo.ref
+		}
+	}
+	if err = git(dir, "checkout", "--quiet", "--force", "--detach", target); err != nil {
+		return nil, fmt.Errorf("checkout %s: %w", target, err)
+	}
+
+	repo.DiskRepository = NewDiskRepository(dir)
+	return
+}
+
+// URL returns the remote url of the repository.
+func (self *GitRepository) URL() string { return self.url }
+
+// Ref returns the checked out ref or an empty string for the default branch.
+func (self *GitRepository) Ref() string { return self.ref }
+
+// Stale returns the error that prevented fetching the remote when the
+// repository was opened or nil if the checkout is up to date.
+func (self *GitRepository) Stale() error { return self.stale }
+
+// gitCacheName returns a directory name for a git cache of url checked out
+// at ref.
+func gitCacheName(url, ref string) string {
+	var (
+		sum  = sha256.Sum256([]byte(url + "@" + ref))
+		name = strings.TrimSuffix(path.Base(strings.ReplaceAll(url, ":", "/")), ".git")
+	)
+	return name + "-" + hex.EncodeToString(sum[:6])
+}
+
+// git runs git with args in dir. If the command fails the returned error
+// contains git error output.
+func git(dir string, args ...string) (err error) {
+	var (
+		cmd    = exec.Command("git", args...)
+		stderr bytes.Buffer
+	)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+	}
+	return
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrg/xdg"
+)
+
+func TestParseGitRepositoryPath(t *testing.T) {
+	for _, test := range []struct {
+		path, url, ref string
+	}{
+		{"git+https://host/org/templates.git", "https://host/org/templates.git", ""},
+		{"git+https://host/org/templates.git@v1.2", "https://host/org/templates.git", "v1.2"},
+		{"git+https://user@host/templates.git", "https://user@host/templates.git", ""},
+		{"git+https://user@host/templates.git@main", "https://user@host/templates.git", "main"},
+		{"git+https://host/templates.git@feature/x", "https://host/templates.git", "feature/x"},
+		{"git+file:///srv/templates.git@v1", "file:///srv/templates.git", "v1"},
+		{"git@host:org/templates.git", "git@host:org/templates.git", ""},
+		{"git@host:org/templates.git@v1.2", "git@host:org/templates.git", "v1.2"},
+		{"git@host:org/templates.git@feature/x", "git@host:org/templates.git", "feature/x"},
+	} {
+		var url, ref = ParseGitRepositoryPath(test.path)
+		if url != test.url || ref != test.ref {
+			t.Errorf("ParseGitRepositoryPath(%q) = %q, %q, want %q, %q", test.path, url, ref, test.url, test.ref)
+		}
+	}
+}
+
+func TestOpenGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	var cache = xdg.CacheHome
+	xdg.CacheHome = t.TempDir()
+	defer func() { xdg.CacheHome = cache }()
+
+	var (
+		root   = t.TempDir()
+		bare   = filepath.Join(root, "templates.git")
+		work   = filepath.Join(root, "work")
+		remote = GitPrefix + "file://" + filepath.ToSlash(bare)
+	)
+	var run = func(dir string, args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		if err := git(dir, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	var write = func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(work, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var read = func(repo Repository, name string) string {
+		t.Helper()
+		var data, err = repo.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	run("", "init", "--quiet", "--bare", "--initial-branch=main", bare)
+	run("", "init", "--quiet", "--initial-branch=main", work)
+	write("file.txt", "v1")
+	run(work, "add", "-A")
+	run(work, "commit", "--quiet", "-m", "v1")
+	run(work, "tag", "v1")
+	run(work, "checkout", "--quiet", "-b", "feature/x")
+	write("file.txt", "feature")
+	run(work, "commit", "--quiet", "-am", "feature")
+	run(work, "checkout", "--quiet", "main")
+	write("file.txt", "v2")
+	run(work, "commit", "--quiet", "-am", "v2")
+	run(work, "remote", "add", "origin", bare)
+	run(work, "push", "--quiet", "--tags", "origin", "main", "feature/x")
+
+	// Clone.
+	var head, err = OpenGitRepository(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := read(head, "file.txt"); s != "v2" {
+		t.Fatalf("default branch: got %q, want %q", s, "v2")
+	}
+
+	// Refs are checked out into separate caches.
+	var tag, branch *GitRepository
+	if tag, err = OpenGitRepository(remote + "@v1"); err != nil {
+		t.Fatal(err)
+	}
+	if branch, err = OpenGitRepository(remote + "@feature/x"); err != nil {
+		t.Fatal(err)
+	}
+	if s := read(tag, "file.txt"); s != "v1" {
+		t.Fatalf("tag: got %q, want %q", s, "v1")
+	}
+	if s := read(branch, "file.txt"); s != "feature" {
+		t.Fatalf("branch: got %q, want %q", s, "feature")
+	}
+	if s := read(head, "file.txt"); s != "v2" {
+		t.Fatalf("default branch after opening refs: got %q, want %q", s, "v2")
+	}
+
+	// Fetch.
+	write("file.txt", "v3")
+	run(work, "commit", "--quiet", "-am", "v3")
+	run(work, "push", "--quiet", "origin", "main")
+	if head, err = OpenGitRepository(remote); err != nil {
+		t.Fatal(err)
+	}
+	if s := read(head, "file.txt"); s != "v3" {
+		t.Fatalf("fetch: got %q, want %q", s, "v3")
+	}
+	if head.Stale() != nil {
+		t.Fatalf("fetch: unexpected stale error: %v", head.Stale())
+	}
+
+	// Offline.
+	if err = os.RemoveAll(bare); err != nil {
+		t.Fatal(err)
+	}
+	if head, err = OpenGitRepository(remote); err != nil {
+		t.Fatalf("offline: %v", err)
+	}
+	if head.Stale() == nil {
+		t.Fatal("offline: expected stale error")
+	}
+	if s := read(head, "file.txt"); s != "v3" {
+		t.Fatalf("offline: got %q, want %q", s, "v3")
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// irregularPlurals maps singular forms of English nouns not covered by
+// pluralRules to their plural forms.
+var irregularPlurals = map[string]string{
+	"person":     "people",
+	"man":        "men",
+	"woman":      "women",
+	"child":      "children",
+	"mouse":      "mice",
+	"goose":      "geese",
+	"foot":       "feet",
+	"tooth":      "teeth",
+	"ox":         "oxen",
+	"index":      "indices",
+	"matrix":     "matrices",
+	"vertex":     "vertices",
+	"medium":     "media",
+	"analysis":   "analyses",
+	"criterion":  "criteria",
+	"phenomenon": "phenomena",
+	"knife":      "knives",
+	"life":       "lives",
+	"wife":       "wives",
+	"leaf":       "leaves",
+	"half":       "halves",
+	"wolf":       "wolves",
+	"shelf":      "shelves",
+	"self":       "selves",
+	"thief":      "thieves",
+	"hero":       "heroes",
+	"potato":     "potatoes",
+	"tomato":     "tomatoes",
+	"echo":       "echoes",
+	"movie":      "movies",
+	"bus":        "buses",
+	"status":     "statuses",
+	"alias":      "aliases",
+	"atlas":      "atlases",
+	"bias":       "biases",
+	"canvas":     "canvases",
+	"gas":        "gases",
+}
+
+// uncountables are English nouns with the same singular and plural form.
+var uncountables = map[string]bool{
+	"data":        true,
+	"metadata":    true,
+	"equipment":   true,
+	"information": true,
+	"money":       true,
+	"species":     true,
+	"series":      true,
+	"fish":        true,
+	"sheep":       true,
+	"deer":        true,
+	"news":        true,
+}
+
+// inflectionRule replaces suffix of a lower case word with replacement.
+type inflectionRule struct {
+	suffix, replacement string
+}
+
+// pluralRules are applied in order, the first matching rule applies.
+var pluralRules = []inflectionRule{
+	{"quiz", "quizzes"},
+	{"ay", "ays"}, {"ey", "eys"}, {"oy", "oys"}, {"uy", "uys"},
+	{"y", "ies"},
+	{"ch", "ches"}, {"sh", "shes"}, {"ss", "sses"},
+	{"s", "ses"}, {"x", "xes"}, {"z", "zes"},
+	{"", "s"},
+}
+
+// singularRules are applied in order, the first matching rule applies.
+var singularRules = []inflectionRule{
+	{"quizzes", "quiz"},
+	{"ies", "y"},
+	{"ches", "ch"}, {"shes", "sh"}, {"sses", "ss"},
+	{"xes", "x"}, {"zes", "z"},
+	{"ss", "ss"}, {"us", "us"}, {"is", "is"},
+	{"s", ""},
+}
+
+// pluralize returns s with its last word in English plural form. The case
+// of the word is retained, so "UserAccount" becomes "UserAccounts". An upper
+// case word that takes a plain "s" is an initialism, so "ID" becomes "IDs".
+// A word that is already plural is retained.
+func pluralize(s string) string {
+	if isPlural(s) {
+		return s
+	}
+	return inflect(s, irregularPlurals, pluralRules)
+}
+
+// isPlural returns true if the last word of s is not a known singular form
+// and is a plural form that singularize undoes, i.e. "categories".
+func isPlural(s string) bool {
+	var words = splitWords(s)
+	if len(words) == 0 {
+		return false
+	}
+	if _, singular := irregularPlurals[strings.ToLower(words[len(words)-1])]; singular {
+		return false
+	}
+	var singular = singularize(s)
+	return singular != s && inflect(singular, irregularPlurals, pluralRules) == s
+}
+
+// singularize returns s with its last word in English singular form. The
+// case of the word is retained, so "UserAccounts" becomes "UserAccount".
+func singularize(s string) string {
+	var singulars = make(map[string]string, 2*len(irregularPlurals))
+	for singular, plural := range irregularPlurals {
+		singulars[plural] = singular
+		// Retain known singular forms, i.e. "gas".
+		if _, exists := singulars[singular]; !exists {
+			singulars[singular] = singular
+		}
+	}
+	return inflect(s, singulars, singularRules)
+}
+
+// inflect replaces the last word of s using irregular forms or the first
+// matching rule.
+func inflect(s string, irregular map[string]string, rules []inflectionRule) string {
+	var words = splitWords(s)
+	if len(words) == 0 {
+		return s
+	}
+	var (
+		word   = words[len(words)-1]
+		prefix = s[:strings.LastIndex(s, word)]
+		suffix = s[len(prefix)+len(word):]
+		lower  = strings.ToLower(word)
+	)
+	// A plural initialism, i.e. "APIs", is inflected from its singular form.
+	if n := len(word) - 1; n > 1 && word[n] == 's' && isUpper(word[:n]) {
+		word, lower = word[:n], lower[:n]
+	}
+	if uncountables[lower] {
+		return s
+	}
+	if form, exists := irregular[lower]; exists {
+		return prefix + matchCase(word, form) + suffix
+	}
+	for _, rule := range rules {
+		if strings.HasSuffix(lower, rule.suffix) {
+			var (
+				stem        = word[:len(word)-len(rule.suffix)]
+				replacement = rule.replacement
+			)
+			// Upper case words keep their case unless only an "s" is
+			// appended, which is the plural form of an initialism.
+			if isUpper(word) && rule.suffix != "" {
+				replacement = strings.ToUpper(replacement)
+			}
+			return prefix + stem + replacement + suffix
+		}
+	}
+	return prefix + word + suffix
+}
+
+// matchCase returns s in upper case if word is an upper case word, with the
+// first letter capitalized if word starts with an upper case letter and as
+// is otherwise.
+func matchCase(word, s string) string {
+	switch {
+	case isUpper(word):
+		return strings.ToUpper(s)
+	case unicode.IsUpper([]rune(word)[0]):
+		return capitalize(s)
+	}
+	return s
+}
+
+// isUpper returns true if word is longer than a letter and in upper case.
+func isUpper(word string) bool {
+	return len([]rune(word)) > 1 && strings.ToUpper(word) == word
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import "testing"
+
+func TestInflect(t *testing.T) {
+	for _, test := range []struct {
+		singular, plural string
+	}{
+		{"user", "users"},
+		{"UserAccount", "UserAccounts"},
+		{"user_account", "user_accounts"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"box", "boxes"},
+		{"match", "matches"},
+		{"class", "classes"},
+		{"quiz", "quizzes"},
+		{"Person", "People"},
+		{"child", "children"},
+		{"PERSON", "PEOPLE"},
+		{"index", "indices"},
+		{"status", "statuses"},
+		{"data", "data"},
+		{"sheep", "sheep"},
+		{"CITY", "CITIES"},
+		{"ID", "IDs"},
+		{"userID", "userIDs"},
+		{"URL", "URLs"},
+		{"API", "APIs"},
+		{"URI", "URIs"},
+		{"CLI", "CLIs"},
+		{"userAPI", "userAPIs"},
+		{"gas", "gases"},
+		{"bus", "buses"},
+		{"analysis", "analyses"},
+		{"user ID", "user IDs"},
+		{"", ""},
+	} {
+		if out := pluralize(test.singular); out != test.plural {
+			t.Errorf("plural %q: got %q, want %q", test.singular, out, test.plural)
+		}
+		if out := singularize(test.plural); out != test.singular {
+			t.Errorf("singular %q: got %q, want %q", test.plural, out, test.singular)
+		}
+		if out := pluralize(test.plural); out != test.plural {
+			t.Errorf("plural of plural %q: got %q", test.plural, out)
+		}
+		if out := singularize(test.singular); out != test.singular {
+			t.Errorf("singular of singular %q: got %q", test.singular, out)
+		}
+	}
+}
@@ -126,6 +126,56 @@ Prompt:
 	return
 }
 
+// AskMultiChoice asks for zero or more of choices separated by commas and
+// returns them. If an empty string is entered the function returns values in
+// def which is a comma separated list of choices. If a value other than one of
+// choices is entered the prompt is repeated. Choices may be tab delimited like
+// in AskChoice.
+func (self *Interrogator) AskMultiChoice(def string, choices ...string) (result []string, err error) {
+	for _, choice := range choices {
+		if choice == "" {
+			return nil, errors.New("askmultichoice: empty string in choices")
+		}
+	}
+	var wr = tabwriter.NewWriter(self.rw, 2, 2, 2, 32, 0)
+	for _, v := range choices {
+		fmt.Fprintf(wr, "%s\n", v)
+	}
+	if err = wr.Flush(); err != nil {
+		return
+	}
+Prompt:
+	for {
+		self.Printf("Choose values separated by commas [%s]: ", def)
+		var input string
+		if input, err = self.rw.ReadString('\n'); err != nil {
+			return
+		}
+		if input = strings.TrimSpace(input); input == "" {
+			input = def
+		}
+		result = nil
+		for _, value := range strings.Split(input, ",") {
+			if value = strings.TrimSpace(value); value == "" {
+				continue
+			}
+			var valid bool
+			for _, choice := range choices {
+				choice, _, _ = strings.Cut(choice, "\t")
+				if valid = value == choice; valid {
+					break
+				}
+			}
+			if !valid {
+				self.Printf("Invalid choice '%s', try again.\n", value)
+				continue Prompt
+			}
+			result = append(result, value)
+		}
+		return
+	}
+}
+
 // AskYesNo asks for a choice between "yes" or a "no" using AskChoice.
 // If an empty string is entered the function returns def.
 // If a word other than "yes" and "no" is entered the prompt is repeated.
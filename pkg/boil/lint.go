@@ -0,0 +1,506 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/vedranvuk/bast/pkg/bast"
+)
+
+// LintSeverity is the severity of a LintIssue.
+type LintSeverity string
+
+const (
+	// LintError is a problem that breaks a Template or the Repository.
+	LintError LintSeverity = "error"
+	// LintWarning is a problem that may be intentional or depends on the
+	// system boil is run on.
+	LintWarning LintSeverity = "warning"
+)
+
+// Names of checks performed by Lint.
+const (
+	// LintValidate reports Metafile validation errors, see Metafile.Validate.
+	LintValidate = "validate"
+	// LintOrphanFile reports files in Template directories that are not
+	// listed by any Metafile.
+	LintOrphanFile = "orphan-file"
+	// LintDuplicateName reports Templates that share a Name.
+	LintDuplicateName = "duplicate-name"
+	// LintGroupCycle reports Groups that include themselves.
+	LintGroupCycle = "group-cycle"
+	// LintRequireCycle reports Templates that require themselves.
+	LintRequireCycle = "require-cycle"
+	// LintTemplateParse reports Template files and partials that are not valid
+	// text/template files.
+	LintTemplateParse = "template-parse"
+	// LintActionProgram reports Action programs that are not found in PATH.
+	LintActionProgram = "action-program"
+	// LintUnusedPrompt reports prompts whose variable is not used anywhere in
+	// the Template or its child Templates.
+	LintUnusedPrompt = "unused-prompt"
+)
+
+// LintIssue is a problem found in a Repository by Lint.
+type LintIssue struct {
+	// Severity is the issue severity.
+	Severity LintSeverity `json:"severity"`
+	// Check is the name of the check that found the issue.
+	Check string `json:"check"`
+	// Repository is the name of the linted repository, if known.
+	Repository string `json:"repository,omitempty"`
+	// Template is the path of the Template the issue concerns.
+	Template string `json:"template,omitempty"`
+	// File is the path of the file the issue concerns relative to the
+	// repository root.
+	File string `json:"file,omitempty"`
+	// Field is the location of the problem in a Metafile, if applicable.
+	// See ValidationError.
+	Field string `json:"field,omitempty"`
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+// String implements fmt.Stringer.
+func (self *LintIssue) String() string {
+	var sb strings.Builder
+	sb.WriteString(string(self.Severity))
+	sb.WriteString(": ")
+	if self.Repository != "" {
+		sb.WriteString(self.Repository)
+		sb.WriteString(":")
+	}
+	if self.File != "" {
+		sb.WriteString(self.File)
+	} else {
+		sb.WriteString(self.Template)
+	}
+	if self.Field != "" {
+		sb.WriteString(": ")
+		sb.WriteString(self.Field)
+	}
+	fmt.Fprintf(&sb, ": %s (%s)", self.Message, self.Check)
+	return sb.String()
+}
+
+// LintIssues is a list of LintIssue.
+type LintIssues []*LintIssue
+
+// Errors returns the number of issues in self with LintError severity.
+func (self LintIssues) Errors() (n int) {
+	for _, issue := range self {
+		if issue.Severity == LintError {
+			n++
+		}
+	}
+	return
+}
+
+// Lint checks all Templates in repo and returns the issues found or an error
+// if the repository could not be read.
+//
+// In addition to validating each Metafile it reports files in Template
+// directories that no Metafile lists, Templates that share a name, Groups
+// that include themselves, Templates that require themselves, Template files
+// that fail to parse, Action programs not found in PATH and prompts whose
+// variables are never used.
+//
+// Templates that extend other Templates are checked merged with them, see
+// ResolveExtends. Files inherited from an extended Template are parsed only
+// with the Template that defines them.
+//
+// Issues are sorted by Template path.
+func Lint(repo Repository) (issues LintIssues, err error) {
+
+	var metamap Metamap
+	if metamap, err = repo.LoadMetamap(); err != nil {
+		return nil, err
+	}
+
+	var l = &linter{
+		repo:      repo,
+		templates: make(map[string]*Metafile),
+	}
+	for key, meta := range metamap {
+		if strings.Contains(key, "#") {
+			continue
+		}
+		// Templates whose extends fail to resolve are checked as they are,
+		// the error is reported by validate.
+		if resolved, err := ResolveExtends(repo, meta); err == nil {
+			meta = resolved
+		}
+		l.templates[filepath.Clean(key)] = meta
+		l.paths = append(l.paths, filepath.Clean(key))
+	}
+	sort.Strings(l.paths)
+
+	for _, f := range []func() error{
+		l.validate,
+		l.orphanFiles,
+		l.duplicateNames,
+		l.groupCycles,
+		l.requireCycles,
+		l.parseFiles,
+		l.actionPrograms,
+		l.unusedPrompts,
+	} {
+		if err = f(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		return l.issues[i].Template < l.issues[j].Template
+	})
+
+	return l.issues, nil
+}
+
+// linter holds the state of a Lint run.
+type linter struct {
+	repo Repository
+	// templates maps Template paths to their Metafiles with extends
+	// resolved.
+	templates map[string]*Metafile
+	// paths are sorted keys of templates.
+	paths  []string
+	issues LintIssues
+}
+
+// add adds a new issue to self.
+func (self *linter) add(severity LintSeverity, check, tmpl, file, field, format string, args ...any) {
+	self.issues = append(self.issues, &LintIssue{
+		Severity: severity,
+		Check:    check,
+		Template: tmpl,
+		File:     file,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// validate adds validation errors of each Metafile.
+func (self *linter) validate() (err error) {
+	for _, path := range self.paths {
+		var errs ValidationErrors
+		if err = self.templates[path].Validate(self.repo); err != nil {
+			if !errors.As(err, &errs) {
+				return fmt.Errorf("validate %s: %w", path, err)
+			}
+			for _, e := range errs {
+				self.add(LintError, LintValidate, path, e.File, e.Field, "%s", e.Message)
+			}
+		}
+	}
+	return nil
+}
+
+// orphanFiles adds files inside Template directories not listed by any
+// Metafile. Metafiles, ".gitkeep" files and partials are ignored.
+func (self *linter) orphanFiles() (err error) {
+	var listed = make(map[string]bool)
+	for _, path := range self.paths {
+		for _, file := range self.templates[path].Files {
+			listed[file.Source(path)] = true
+		}
+	}
+	return self.repo.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == PartialsDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if IsMetafileName(d.Name()) || d.Name() == ".gitkeep" || listed[path] {
+			return nil
+		}
+		if owner := self.owner(path); owner != "" {
+			self.add(LintError, LintOrphanFile, owner, path, "", "file is not listed in any metafile")
+		}
+		return nil
+	})
+}
+
+// owner returns the path of the Template whose directory is the closest
+// parent of file or an empty string if file is not in a Template directory.
+func (self *linter) owner(file string) string {
+	for dir := filepath.Dir(file); ; dir = filepath.Dir(dir) {
+		if _, exists := self.templates[dir]; exists {
+			return dir
+		}
+		if dir == "." || dir == string(filepath.Separator) {
+			return ""
+		}
+	}
+}
+
+// duplicateNames adds Templates whose Name is used by a Template before them.
+func (self *linter) duplicateNames() error {
+	var names = make(map[string]string)
+	for _, path := range self.paths {
+		var meta = self.templates[path]
+		if meta.Name == "" {
+			continue
+		}
+		if first, exists := names[meta.Name]; exists {
+			self.add(LintError, LintDuplicateName, path, meta.File(), "name",
+				"template name '%s' is also used by template '%s'", meta.Name, first)
+			continue
+		}
+		names[meta.Name] = path
+	}
+	return nil
+}
+
+// groupCycles adds Groups that directly or indirectly include themselves.
+func (self *linter) groupCycles() error {
+	var keys []string
+	for _, path := range self.paths {
+		for _, group := range self.templates[path].Groups {
+			keys = append(keys, path+"#"+group.Name)
+		}
+	}
+	findCycles(keys, self.groupMembers, func(cycle []string) {
+		self.add(LintError, LintGroupCycle, cycle[0], "", "",
+			"group cycle: %s", strings.Join(cycle, " -> "))
+	})
+	return nil
+}
+
+// requireCycles adds Templates that directly or indirectly require
+// themselves.
+func (self *linter) requireCycles() error {
+	findCycles(self.paths, self.requirements, func(cycle []string) {
+		self.add(LintError, LintRequireCycle, cycle[0], "", "",
+			"requires cycle: %s", strings.Join(cycle, " -> "))
+	})
+	return nil
+}
+
+// requirements returns paths of Templates required by the Template at path.
+// Invalid requirements are reported by validate and ignored.
+func (self *linter) requirements(path string) (paths []string) {
+	var meta = self.templates[path]
+	if meta == nil {
+		return nil
+	}
+	for _, s := range meta.Requires {
+		if req, err := ParseRequirement(s); err == nil {
+			paths = append(paths, req.Path)
+		}
+	}
+	return
+}
+
+// findCycles visits the graph of nodes reachable from keys using edges,
+// depth first, and calls report with each cycle found. A cycle starts and
+// ends with the same node.
+func findCycles(keys []string, edges func(string) []string, report func(cycle []string)) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	var (
+		state = make(map[string]int)
+		stack []string
+		visit func(key string)
+	)
+	visit = func(key string) {
+		switch state[key] {
+		case visited:
+			return
+		case visiting:
+			var i = len(stack) - 1
+			for i > 0 && stack[i] != key {
+				i--
+			}
+			report(append(append([]string{}, stack[i:]...), key))
+			return
+		}
+		state[key] = visiting
+		stack = append(stack, key)
+		for _, next := range edges(key) {
+			visit(next)
+		}
+		stack = stack[:len(stack)-1]
+		state[key] = visited
+	}
+	for _, key := range keys {
+		visit(key)
+	}
+}
+
+// groupMembers returns paths of Templates and Groups included by the Group
+// addressed by key or nil if key does not address a Group.
+func (self *linter) groupMembers(key string) (members []string) {
+	var path, name, found = strings.Cut(key, "#")
+	if !found {
+		return nil
+	}
+	var meta = self.templates[filepath.Clean(path)]
+	if meta == nil {
+		return nil
+	}
+	for _, group := range meta.Groups {
+		if group.Name != name {
+			continue
+		}
+		for _, nested := range group.Groups {
+			members = append(members, path+"#"+nested)
+		}
+		for _, tmpl := range group.Templates {
+			var member, sub, found = strings.Cut(tmpl.Template, "#")
+			member = filepath.Join(group.Dir(path), member)
+			if found {
+				member += "#" + sub
+			}
+			members = append(members, member)
+		}
+	}
+	return
+}
+
+// parseFiles adds Template files that fail to parse as text/template files.
+func (self *linter) parseFiles() (err error) {
+	var funcs = (&Data{Bast: bast.New()}).FuncMap()
+	for _, path := range self.paths {
+		for i, file := range self.templates[path].Files {
+			if file.Template != "" {
+				// Parsed with the Template that defines it.
+				continue
+			}
+			var (
+				name = file.Source(path)
+				data []byte
+			)
+			if data, err = self.repo.ReadFile(name); err != nil {
+				// Missing files are reported by validate.
+				continue
+			}
+			if _, err = template.New(filepath.Base(name)).Funcs(funcs).Parse(string(data)); err != nil {
+				self.add(LintError, LintTemplateParse, path, name, fmt.Sprintf("files[%d]", i), "%v", err)
+			}
+		}
+	}
+	return self.parsePartials(funcs)
+}
+
+// parsePartials adds partials directories available to Templates whose files
+// fail to parse. See PartialDirs.
+func (self *linter) parsePartials(funcs template.FuncMap) (err error) {
+	var dirs []string
+	if dirs, err = existingPartialDirs(self.repo, ancestorDirs(self.paths...)...); err != nil {
+		return
+	}
+	for _, dir := range dirs {
+		if _, err = ParsePartials(self.repo, []string{dir}, funcs); err != nil {
+			self.add(LintError, LintTemplateParse, self.owner(dir), dir, "", "%v", err)
+		}
+	}
+	return nil
+}
+
+// actionPrograms adds Actions whose Program is not found in PATH. Programs
+// that contain placeholders are skipped.
+func (self *linter) actionPrograms() error {
+	for _, path := range self.paths {
+		var meta = self.templates[path]
+		for _, group := range []struct {
+			name    string
+			actions Actions
+		}{
+			{"actions.preParse", meta.Actions.PreParse},
+			{"actions.preExecute", meta.Actions.PreExecute},
+			{"actions.postExecute", meta.Actions.PostExecute},
+		} {
+			for i, action := range group.actions {
+				if action.Program == "" || strings.Contains(action.Program, "{{") || strings.Contains(action.Program, "$") {
+					continue
+				}
+				if _, err := exec.LookPath(action.Program); err != nil {
+					self.add(LintWarning, LintActionProgram, path, meta.File(),
+						fmt.Sprintf("%s[%d].program", group.name, i),
+						"program '%s' not found in PATH", action.Program)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// unusedPrompts adds prompts whose variable is not referenced by files,
+// entry paths, conditions, actions or other prompts of the Template that
+// declares them or any of its child Templates.
+func (self *linter) unusedPrompts() (err error) {
+	for _, path := range self.paths {
+		var meta = self.templates[path]
+		if len(meta.Prompts) == 0 {
+			continue
+		}
+		var text strings.Builder
+		for _, child := range self.paths {
+			if path != "." && child != path && !strings.HasPrefix(child, path+string(filepath.Separator)) {
+				continue
+			}
+			if err = self.writeReferences(&text, self.templates[child]); err != nil {
+				return
+			}
+		}
+		for i, prompt := range meta.Prompts {
+			if prompt.Variable == "" {
+				continue
+			}
+			var name = regexp.QuoteMeta(prompt.Variable)
+			var exp = regexp.MustCompile(`\.Vars\.` + name + `\b|\$\{?` + name + `|"` + name + `"`)
+			if !exp.MatchString(text.String()) {
+				self.add(LintWarning, LintUnusedPrompt, path, meta.File(),
+					fmt.Sprintf("prompts[%d]", i), "variable '%s' is never used", prompt.Variable)
+			}
+		}
+	}
+	return nil
+}
+
+// writeReferences writes all text of meta and its files that may reference
+// variables to w.
+func (self *linter) writeReferences(w *strings.Builder, meta *Metafile) (err error) {
+	for _, entry := range append(append(Entries{}, meta.Directories...), meta.Files...) {
+		fmt.Fprintln(w, entry.Path, entry.When)
+	}
+	for _, file := range meta.Files {
+		var data []byte
+		if data, err = self.repo.ReadFile(file.Source(meta.Path)); err != nil {
+			continue
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+	}
+	for _, prompt := range meta.Prompts {
+		fmt.Fprintln(w, prompt.Default, prompt.When)
+	}
+	for _, actions := range []Actions{meta.Actions.PreParse, meta.Actions.PreExecute, meta.Actions.PostExecute} {
+		for _, action := range actions {
+			fmt.Fprintln(w, action.Program, action.WorkDir, strings.Join(action.Arguments, " "))
+			for key, value := range action.Environment {
+				fmt.Fprintln(w, key, value)
+			}
+		}
+	}
+	return nil
+}
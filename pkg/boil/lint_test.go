@@ -0,0 +1,66 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLintExtends(t *testing.T) {
+
+	var repo = NewFSRepository(fstest.MapFS{
+		"parent/boil.json": {Data: []byte(`{
+			"files": [{"path": "main.go"}],
+			"prompts": [{"variable": "Name"}],
+			"groups": [{"name": "all", "templates": ["sub"]}]
+		}`)},
+		"parent/main.go": {Data: []byte(`{{.Vars.Name}} {{.Vars.License}}`)},
+		"parent/sub/boil.json": {Data: []byte(`{
+			"files": [{"path": "sub.go"}]
+		}`)},
+		"parent/sub/sub.go": {},
+		"child/boil.json": {Data: []byte(`{
+			"extends": "parent",
+			"prompts": [{"variable": "License"}],
+			"groups": [{"name": "more", "groups": ["all"]}]
+		}`)},
+	})
+
+	var issues, err = Lint(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		t.Errorf("unexpected issue: %s", issue)
+	}
+}
+
+func TestLintInheritedGroupCycle(t *testing.T) {
+
+	var repo = NewFSRepository(fstest.MapFS{
+		"parent/boil.json": {Data: []byte(`{
+			"groups": [{"name": "all", "groups": ["base"]}, {"name": "base"}]
+		}`)},
+		"child/boil.json": {Data: []byte(`{
+			"extends": "parent",
+			"groups": [{"name": "base", "groups": ["all"]}]
+		}`)},
+	})
+
+	var issues, err = Lint(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cycles []string
+	for _, issue := range issues {
+		if issue.Check == LintGroupCycle {
+			cycles = append(cycles, issue.Message)
+		}
+	}
+	if len(cycles) != 1 || cycles[0] != "group cycle: child#all -> child#base -> child#all" {
+		t.Errorf("expected a group cycle of inherited group child#all, got %v", issues)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// ManifestDir is the name of the directory in the output directory that
+	// holds the Manifest and base copies of generated files.
+	ManifestDir = ".boil"
+	// ManifestFileName is the name of the Manifest file in ManifestDir.
+	ManifestFileName = "manifest.json"
+	// ManifestBaseDir is the name of the directory in ManifestDir that holds
+	// copies of generated files as they were produced by the Template. They
+	// are used as the common base when merging Template upgrades.
+	ManifestBaseDir = "base"
+)
+
+// Manifest records how the contents of an output directory were generated.
+//
+// It is written to ManifestDir in the output directory after a Template
+// execution and allows the Template to be upgraded by executing a newer
+// version of it with the same variables and merging the changes into
+// generated files the user has since edited.
+type Manifest struct {
+	// TemplatePath is the path of the executed Template as given to the exec
+	// command, possibly prefixed with a repository name and suffixed with a
+	// group name.
+	TemplatePath string `json:"templatePath"`
+	// Version is the Version of the executed Template Metafile.
+	Version string `json:"version,omitempty"`
+	// Created is the time of the execution.
+	Created time.Time `json:"created"`
+	// Vars are the variables the Template was executed with, without
+	// standard variables specific to the machine it was executed on such as
+	// OutputDir and TemplatePath.
+	Vars Variables `json:"vars"`
+	// Files are the generated files.
+	Files []*ManifestFile `json:"files"`
+}
+
+// ManifestFile is a generated file in a Manifest.
+type ManifestFile struct {
+	// Path is the file path relative to the output directory in slash format.
+	Path string `json:"path"`
+	// SHA256 is the hex encoded sha256 hash of the file as it was generated
+	// by the Template.
+	SHA256 string `json:"sha256"`
+}
+
+// ManifestPath returns the path of the Manifest file in outputDir.
+func ManifestPath(outputDir string) string {
+	return filepath.Join(outputDir, ManifestDir, ManifestFileName)
+}
+
+// IsManifestPath returns true if path is ManifestDir in outputDir or a path
+// inside it.
+func IsManifestPath(outputDir, path string) bool {
+	var rel, err = filepath.Rel(outputDir, path)
+	if err != nil {
+		return false
+	}
+	var first, _, _ = strings.Cut(filepath.ToSlash(rel), "/")
+	return first == ManifestDir
+}
+
+// ManifestBasePath returns the path of the base copy of a generated file at
+// relPath, relative to outputDir in slash format.
+func ManifestBasePath(outputDir, relPath string) string {
+	return filepath.Join(outputDir, ManifestDir, ManifestBaseDir, filepath.FromSlash(relPath))
+}
+
+// ReadManifest reads the Manifest from outputDir in output and returns it or
+// an error. If the Manifest does not exist the error wraps fs.ErrNotExist.
+func ReadManifest(output OutputFS, outputDir string) (manifest *Manifest, err error) {
+	var data []byte
+	if data, err = output.ReadFile(ManifestPath(outputDir)); err != nil {
+		return nil, err
+	}
+	manifest = new(Manifest)
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal manifest: %w", err)
+	}
+	return
+}
+
+// Write writes self to outputDir in output.
+func (self *Manifest) Write(output OutputFS, outputDir string) (err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(self, "", "\t"); err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err = output.MkdirAll(filepath.Join(outputDir, ManifestDir), os.ModePerm); err != nil {
+		return
+	}
+	return output.WriteFile(ManifestPath(outputDir), data, 0666)
+}
+
+// AddFile adds a generated file at relPath with content data to self and
+// writes a base copy of data to outputDir in output.
+func (self *Manifest) AddFile(output OutputFS, outputDir, relPath string, data []byte) (err error) {
+	var (
+		hash = sha256.Sum256(data)
+		base = ManifestBasePath(outputDir, relPath)
+	)
+	if err = output.MkdirAll(filepath.Dir(base), os.ModePerm); err != nil {
+		return
+	}
+	if err = output.WriteFile(base, data, 0666); err != nil {
+		return
+	}
+	self.Files = append(self.Files, &ManifestFile{
+		Path:   relPath,
+		SHA256: hex.EncodeToString(hash[:]),
+	})
+	return nil
+}
+
+// File returns the generated file at relPath or nil if not found.
+func (self *Manifest) File(relPath string) *ManifestFile {
+	for _, file := range self.Files {
+		if file.Path == relPath {
+			return file
+		}
+	}
+	return nil
+}
+
+// ReadBase returns the base copy of a generated file at relPath from
+// outputDir in output or an error. If relPath is not a file in self or the
+// base copy does not exist the error wraps fs.ErrNotExist.
+func (self *Manifest) ReadBase(output OutputFS, outputDir, relPath string) ([]byte, error) {
+	if self.File(relPath) == nil {
+		return nil, &fs.PathError{Op: "read", Path: relPath, Err: fs.ErrNotExist}
+	}
+	return output.ReadFile(ManifestBasePath(outputDir, relPath))
+}
@@ -5,12 +5,16 @@
 package boil
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"path/filepath"
 	"sort"
 )
 
-// MetafileName is the name of a file that defines a Boil template.
+// MetafileName is the name of a file that defines a Boil template in the
+// default, JSON format. See MetafileFormats for other formats.
 const MetafileName = "boil.json"
 
 // NewMetafile returns a new metfile initialized to defaults from config.
@@ -23,8 +27,8 @@ func NewMetafile(config *Config) *Metafile {
 		},
 		Version:     "1.0.0",
 		URL:         "https://",
-		Directories: []string{},
-		Files:       []string{},
+		Directories: Entries{},
+		Files:       Entries{},
 		Prompts:     Prompts{},
 		Groups:      []*Group{},
 	}
@@ -37,6 +41,10 @@ func NewMetafile(config *Config) *Metafile {
 // define one or more Group definitions with various combinations of theese
 // child templates to be executed as part of the parent Template.
 type Metafile struct {
+	// Schema is an optional JSON Schema reference used by editors.
+	// See MetafileSchema.
+	Schema string `json:"$schema,omitempty"`
+
 	// Name is the Template name.
 	// It is the last element of the template path when addressing it.
 	// For example 'apps/<name>'
@@ -52,8 +60,8 @@ type Metafile struct {
 	Author *Author `json:"author,omitempty"`
 
 	// Version is the template version, set manually used to help keep track of
-	// Template changes. SemVer will be understood if this becomes important to
-	// machine; currently just a meta field possibly useful to user.
+	// Template changes. It must be a semantic version if other Templates
+	// require this Template with a version constraint, see Requires.
 	// By default the version is set at '1.0.0' when generating a Template.
 	Version string `json:"version,omitempty"`
 
@@ -61,6 +69,29 @@ type Metafile struct {
 	// machine but is just an additional meta field. It is empty by default.
 	URL string `json:"url,omitempty"`
 
+	// Extends is an optional path of a Template in the same repository this
+	// Template extends, i.e. "apps/app". This Template inherits Files,
+	// Directories, Prompts, Actions, Groups and Conflicts of the extended
+	// Template and may add entries, override inherited entries with the same
+	// name or remove them by name using Remove. Inherited files are read from
+	// the extended Template directory. Extended Templates may extend other
+	// Templates. See ResolveExtends.
+	Extends string `json:"extends,omitempty"`
+
+	// Remove lists entries inherited from the Template defined by Extends to
+	// remove from this Template, by name.
+	Remove *Removals `json:"remove,omitempty"`
+
+	// Requires is a list of Templates in the same repository this Template
+	// depends on. Each entry is a Template path optionally followed by a
+	// constraint on the required Template Version, i.e.
+	// "go/utils/stringer >=1.2", see ParseRequirement.
+	//
+	// Required Templates are executed before this Template, recursively.
+	// A Template required more than once is executed once and prompts for
+	// the same variable are presented once. See TasksFromMetafile.
+	Requires []string `json:"requires,omitempty"`
+
 	// Files is a list of paths to files inside the Template directory that
 	// will get executed and written to the output target directory retaining
 	// its path relative to the Template directory.
@@ -74,16 +105,35 @@ type Metafile struct {
 	// Paths of files defined in Files may contain placeholder values which will
 	// get expended to actual values during Template execution.
 	// A placeholder is defined with a "$" prefix, immediately followed by the
-	// name of a Variable.
-	Files []string `json:"files"`
+	// name of a Variable and optional filters, i.e. "$ProjectName|snake".
+	// Paths that expand to a path outside of the output directory are an
+	// error. See Variables.ExpandPath for the syntax.
+	//
+	// An entry is either a path string or an object with a "path" and an
+	// optional "when" condition, see Entry.
+	Files Entries `json:"files"`
 
 	// Directories is a list of directories to create in the target directory.
 	// Placeholders are supported like with Files. Directories defined in this
 	// list will be created regardless of wether they contain any of the
-	// files defined by Files or if they exist phisically in the Template
-	// directory. They will be created in the template however when creating a
-	// Template with the "snap" command.
-	Directories []string `json:"directories"`
+	// files defined by Files. Like Files, they must exist in the Template
+	// directory; they are created in the template when creating a Template
+	// with the "snap" command. Put a ".gitkeep" file in empty directories of
+	// Templates stored in git.
+	//
+	// Entries are defined like in Files.
+	Directories Entries `json:"directories"`
+
+	// Conflicts is a list of rules that define how output files that already
+	// exist in the output directory are handled, per file pattern. The first
+	// rule whose pattern matches an output file path relative to the output
+	// directory defines the ConflictPolicy for the file. Files not matched by
+	// any rule use the policy given for the execution, which by default fails
+	// the execution.
+	//
+	// This allows fragment Templates, i.e. a Template that adds editor
+	// settings, to be safely executed into existing projects.
+	Conflicts ConflictRules `json:"conflicts,omitempty"`
 
 	// Prompts is a list of prompts to present to the user before Template
 	// execution via stdin to input values for variables the prompts define.
@@ -93,7 +143,7 @@ type Metafile struct {
 	//
 	// Prompts can each define a regular expression to use for input validation.
 	// A failed validation will then re-prompt the user for value.
-	Prompts Prompts `json:"prompts,imitempty"`
+	Prompts Prompts `json:"prompts,omitempty"`
 
 	// Actions are groups of definitions of external actions to perform at
 	// various stages of Template execution. In each Action group
@@ -113,7 +163,8 @@ type Metafile struct {
 		// executed in the order they are defined. It is called after the
 		// variables were defined by parsing command line input, files given as
 		// variable data on command line and all other input methods and are
-		// available as expandable placeholders in action definition.
+		// available to the action definition which is executed as a
+		// text/template with the boil template functions, see FuncMap.
 		//
 		// This useful to execute some Template setup commands that depend on
 		// Template variables.
@@ -121,8 +172,8 @@ type Metafile struct {
 
 		// PostExecute is a slice of actions to perform after the template was
 		// executed, in order they are defined. This is useful for performing
-		// cleanup operations. Variables will be available for expansion in the
-		// action definition via placeholders.
+		// cleanup operations. Variables and template functions are available
+		// to the action definition as with PreExecute.
 		PostExecute Actions `json:"postExecute,omitempty"`
 	} `json:"actions,omitempty"`
 
@@ -138,11 +189,11 @@ type Metafile struct {
 	// of that template. This allows for defining segmented and multilayered
 	// permutations of templates organized in a parent-child manner.
 	//
-	// A Group template is addressed by its path in a manner that the last
-	// element of the path that matches Metafile.Name is instead replaced with
-	// the name of the Group. So for instance, if a template 'apps/versatileapp'
-	// defines groups 'base', and 'complete', to execute the 'base' Group the
-	// path would be 'apps/base'.
+	// A Group is addressed by the Template path followed by a "#" and the
+	// name of the Group. For instance, if a template 'apps/versatileapp'
+	// defines groups 'base' and 'complete', to execute the 'base' Group the
+	// path would be 'apps/versatileapp#base'. The Template is executed
+	// first, followed by the members of the Group, see Group.
 	Groups []*Group `json:"groups,omitempty"`
 
 	// Path is where metafile resides, relative to the repository root.
@@ -150,14 +201,23 @@ type Metafile struct {
 	//
 	// Path is not stored with the template, it's runtime only.
 	Path string `json:"-"`
+
+	// Format is the format of the file the Metafile was loaded from and is
+	// saved to. If empty, MetafileJSON is used.
+	//
+	// Format is not stored with the template, it's runtime only.
+	Format MetafileFormat `json:"-"`
+
+	// extended are paths of Templates the Metafile was merged with by
+	// ResolveExtends, the most distant first. It is empty if the Metafile
+	// was not resolved.
+	extended []string
 }
 
-// Validate validates that metadata is properly formatted.
-// It checks that multis point to valid Templates in the repo.
-// It checks for duplicate template definitions.
-func (self Metafile) Validate(repo Repository) error {
-	// TODO Implement Metafile.Validate
-	return nil
+// File returns the path of the Metafile file relative to the repository
+// root.
+func (self *Metafile) File() string {
+	return filepath.Join(self.Path, self.Format.Filename())
 }
 
 // ExecPreParseActions executes all PreParse Actions defined in the Metafile.
@@ -191,6 +251,15 @@ func (self *Metafile) Print(wr io.Writer) {
 	fmt.Fprintf(wr, "Author Homepage:\t%s\n", self.Author.Homepage)
 	fmt.Fprintf(wr, "Version:\t%s\n", self.Version)
 	fmt.Fprintf(wr, "URL:\t%s\n", self.URL)
+	if self.Extends != "" {
+		fmt.Fprintf(wr, "Extends:\t%s\n", self.Extends)
+	}
+	if len(self.Requires) > 0 {
+		fmt.Fprintf(wr, "Requires:\t\n")
+		for _, req := range self.Requires {
+			fmt.Fprintf(wr, "\t%s\n", req)
+		}
+	}
 	fmt.Fprintf(wr, "Directories:\t\n")
 	for _, dir := range self.Directories {
 		fmt.Fprintf(wr, "\t%s\n", dir)
@@ -204,9 +273,14 @@ func (self *Metafile) Print(wr io.Writer) {
 		fmt.Fprintf(wr, "Variable:\t%s\n", prompt.Variable)
 		fmt.Fprintf(wr, "Description:\t%s\n", prompt.Description)
 		fmt.Fprintf(wr, "RegExp:\t%s\n", prompt.RegExp)
+		fmt.Fprintf(wr, "Type:\t%s\n", prompt.GetType())
+		fmt.Fprintf(wr, "Default:\t%s\n", prompt.Default)
+		fmt.Fprintf(wr, "Choices:\t%v\n", prompt.Choices)
+		fmt.Fprintf(wr, "When:\t%s\n", prompt.When)
 	}
 	fmt.Fprintf(wr, "PreParse Actions:\t\n")
 	for _, action := range self.Actions.PreParse {
+		fmt.Fprintf(wr, "Name:\t%s\n", action.Name)
 		fmt.Fprintf(wr, "Description:\t%s\n", action.Description)
 		fmt.Fprintf(wr, "Program:\t%s\n", action.Program)
 		fmt.Fprintf(wr, "Arguments:\t%v\n", action.Arguments)
@@ -215,6 +289,7 @@ func (self *Metafile) Print(wr io.Writer) {
 	}
 	fmt.Fprintf(wr, "PreExecute Actions:\t\n")
 	for _, action := range self.Actions.PreExecute {
+		fmt.Fprintf(wr, "Name:\t%s\n", action.Name)
 		fmt.Fprintf(wr, "Description:\t%s\n", action.Description)
 		fmt.Fprintf(wr, "Program:\t%s\n", action.Program)
 		fmt.Fprintf(wr, "Arguments:\t%v\n", action.Arguments)
@@ -223,6 +298,7 @@ func (self *Metafile) Print(wr io.Writer) {
 	}
 	fmt.Fprintf(wr, "PostExecute Actions:\t\n")
 	for _, action := range self.Actions.PostExecute {
+		fmt.Fprintf(wr, "Name:\t%s\n", action.Name)
 		fmt.Fprintf(wr, "Description:\t%s\n", action.Description)
 		fmt.Fprintf(wr, "Program:\t%s\n", action.Program)
 		fmt.Fprintf(wr, "Arguments:\t%v\n", action.Arguments)
@@ -233,7 +309,13 @@ func (self *Metafile) Print(wr io.Writer) {
 	for _, group := range self.Groups {
 		fmt.Fprintf(wr, "Name:\t%s\n", group.Name)
 		fmt.Fprintf(wr, "Description:\t%s\n", group.Description)
-		fmt.Fprintf(wr, "Templates:\t%v\n", group.Templates)
+		if len(group.Groups) > 0 {
+			fmt.Fprintf(wr, "Groups:\t%v\n", group.Groups)
+		}
+		fmt.Fprintf(wr, "Templates:\t\n")
+		for _, member := range group.Templates {
+			fmt.Fprintf(wr, "\t%s\n", member)
+		}
 	}
 }
 
@@ -252,17 +334,188 @@ type Author struct {
 	ModulePrefix string `json:"modulePrefix,omitempty"`
 }
 
+// Entry is a file or a directory entry in Metafile Files or Directories.
+//
+// In a metafile an Entry is either a path string or an object with a "path"
+// and a "when" field:
+//
+//	"files": [
+//		"main.go",
+//		{ "path": "Dockerfile", "when": ".Vars.UseDocker" }
+//	]
+type Entry struct {
+	// Path is the path of the file or directory relative to the Template
+	// directory. It may contain placeholders.
+	Path string `json:"path"`
+	// When is an optional condition. It is a text/template pipeline evaluated
+	// with Template Data after all prompts were presented. The entry is
+	// executed only if the pipeline value is not empty, as defined by the
+	// "if" template action. If When is empty the entry is always executed.
+	When string `json:"when,omitempty"`
+	// Template is the path of the Template that defines the entry if it was
+	// inherited from an extended Template. See Metafile.Extends.
+	//
+	// Template is not stored with the template, it's runtime only.
+	Template string `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// It unmarshals self from a path string or an object.
+func (self *Entry) UnmarshalJSON(data []byte) (err error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("\"")) {
+		*self = Entry{}
+		return json.Unmarshal(data, &self.Path)
+	}
+	type entry Entry
+	var e entry
+	if err = json.Unmarshal(data, &e); err != nil {
+		return
+	}
+	*self = Entry(e)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+// It marshals self as a path string if When is empty.
+func (self *Entry) MarshalJSON() ([]byte, error) {
+	if self.When == "" {
+		return json.Marshal(self.Path)
+	}
+	type entry Entry
+	return json.Marshal((*entry)(self))
+}
+
+// String implements fmt.Stringer.
+func (self *Entry) String() string {
+	var s = self.Path
+	if self.When != "" {
+		s += fmt.Sprintf(" (when %s)", self.When)
+	}
+	if self.Template != "" {
+		s += fmt.Sprintf(" (from %s)", self.Template)
+	}
+	return s
+}
+
+// Source returns the path of the entry relative to the repository root.
+// The entry is relative to the Template at path unless it was inherited.
+func (self *Entry) Source(path string) string {
+	if self.Template != "" {
+		path = self.Template
+	}
+	return filepath.Join(path, self.Path)
+}
+
+// Entries is a slice of *Entry.
+type Entries []*Entry
+
+// Paths returns paths of all entries in self.
+func (self Entries) Paths() (paths []string) {
+	for _, entry := range self {
+		paths = append(paths, entry.Path)
+	}
+	return
+}
+
 // Group defines a group of templates.
 // See Metafile.Groups for details on Group usage.
+//
+// When a Group is executed, Groups it includes are executed first, followed
+// by its Templates in order. Optional Templates are executed only if
+// selected when the Group is executed.
 type Group struct {
 	// Name is the name of the Template Group.
 	Name string `json:"name,omitempty"`
 	// Description is the Group description text.
 	Description string `json:"description,omitempty"`
-	// Templates is a slice of Template names contained in this Group.
-	Templates []string `json:"templates,omitempty"`
+	// Groups is a slice of names of other Groups of the same Template whose
+	// members are included in this Group, i.e. a "full" Group may include a
+	// "minimal" Group and add more Templates.
+	Groups []string `json:"groups,omitempty"`
+	// Templates is a slice of Template members contained in this Group.
+	Templates GroupMembers `json:"templates,omitempty"`
+	// Template is the path of the Template that defines the Group if it was
+	// inherited from an extended Template. Templates are relative to it.
+	// See Metafile.Extends.
+	//
+	// Template is not stored with the template, it's runtime only.
+	Template string `json:"-"`
+}
+
+// Dir returns the path of the directory Templates are relative to. It is the
+// Template path unless the Group was inherited.
+func (self *Group) Dir(path string) string {
+	if self.Template != "" {
+		return self.Template
+	}
+	return path
+}
+
+// GroupMember is a Template included in a Group.
+//
+// In a Metafile a GroupMember is defined either as a Template path string or
+// as an object with a "template" path and optional "description",
+// "optional", "selected" and "vars" fields.
+type GroupMember struct {
+	// Template is the path of the member Template relative to the Template
+	// that defines the Group. It may address a Group of the member Template
+	// using a "#" suffix.
+	Template string `json:"template"`
+	// Description is an optional description of the member presented to the
+	// user when selecting Optional members.
+	Description string `json:"description,omitempty"`
+	// Optional, if true, executes the member only if the user selects it when
+	// the Group is executed.
+	Optional bool `json:"optional,omitempty"`
+	// Selected, if true, selects an Optional member by default.
+	Selected bool `json:"selected,omitempty"`
+	// Vars are variable values that override values of variables with the
+	// same names when the member Template is executed. Prompts of the member
+	// Template for these variables are not presented.
+	Vars Variables `json:"vars,omitempty"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler.
+// It accepts a path string or a GroupMember object.
+func (self *GroupMember) UnmarshalJSON(data []byte) (err error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("\"")) {
+		*self = GroupMember{}
+		return json.Unmarshal(data, &self.Template)
+	}
+	type member GroupMember
+	var m member
+	if err = json.Unmarshal(data, &m); err != nil {
+		return
+	}
+	*self = GroupMember(m)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+// It marshals self as a path string if only Template is set.
+func (self *GroupMember) MarshalJSON() ([]byte, error) {
+	if self.Description == "" && !self.Optional && !self.Selected && len(self.Vars) == 0 {
+		return json.Marshal(self.Template)
+	}
+	type member GroupMember
+	return json.Marshal((*member)(self))
+}
+
+// String implements fmt.Stringer.
+func (self *GroupMember) String() string {
+	var s = self.Template
+	if self.Optional {
+		s += " (optional)"
+	}
+	if len(self.Vars) > 0 {
+		s += fmt.Sprintf(" (vars %v)", map[string]any(self.Vars))
+	}
+	return s
+}
+
+// GroupMembers is a slice of *GroupMember.
+type GroupMembers []*GroupMember
+
 // Prompt defines a prompt to the user for input of variable values.
 // See Metafile.Prompts for details on Prompt usage.
 type Prompt struct {
@@ -279,6 +532,26 @@ type Prompt struct {
 	// Optional if true will not trigger an error if the variable was assigned
 	// an empty value.
 	Optional bool `json:"optional,omitempty"`
+	// Type is the type of the value the prompt asks for and the type of the
+	// value stored in the Variable. If empty, PromptString is used.
+	// See PromptType for types.
+	Type PromptType `json:"type,omitempty"`
+	// Default is the default value of the prompt in string format. Values of
+	// list types are comma separated, booleans are "true" or "false".
+	Default string `json:"default,omitempty"`
+	// Choices are the choices offered by PromptChoice and PromptMultiChoice
+	// prompts. A value of those prompts must be one or more of Choices.
+	Choices []string `json:"choices,omitempty"`
+	// When is an optional condition. It is a text/template pipeline that is
+	// evaluated with Template Data containing values of earlier prompts, for
+	// example ".Vars.UseDocker" or "eq .Vars.Database \"postgres\"". The
+	// prompt is presented only if the pipeline value is not empty, as defined
+	// by the "if" template action. If When is empty the prompt is always
+	// presented.
+	//
+	// Default may also use earlier prompt values as it is executed as a
+	// text/template with the same Data.
+	When string `json:"when,omitempty"`
 }
 
 // Prompts is a slice of *Prompt.
@@ -297,6 +570,15 @@ func (self Prompts) FindByVariable(variable string) *Prompt {
 // Metamap maps a path to a template to its *Metafile.
 type Metamap map[string]*Metafile
 
+// add adds meta to self under key and under a key for each of the groups
+// defined by meta. See Repository.LoadMetamap for key format.
+func (self Metamap) add(key string, meta *Metafile) {
+	self[key] = meta
+	for _, group := range meta.Groups {
+		self[fmt.Sprintf("%s#%s", key, group.Name)] = meta
+	}
+}
+
 // Print prints self to stdout.
 func (self Metamap) Print(wr io.Writer) {
 	var a []string
@@ -0,0 +1,328 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// MetafileFormat is the file format of a Metafile.
+type MetafileFormat string
+
+const (
+	// MetafileJSON is the JSON format, stored in "boil.json".
+	MetafileJSON MetafileFormat = "json"
+	// MetafileYAML is the YAML format, stored in "boil.yaml".
+	MetafileYAML MetafileFormat = "yaml"
+	// MetafileTOML is the TOML format, stored in "boil.toml".
+	MetafileTOML MetafileFormat = "toml"
+)
+
+// MetafileFormats lists supported Metafile formats in order of precedence.
+// If a Template directory contains Metafiles in more than one format the
+// first one is used.
+var MetafileFormats = []MetafileFormat{
+	MetafileJSON,
+	MetafileYAML,
+	MetafileTOML,
+}
+
+// Filename returns the name of a Metafile file in format self.
+// An empty format is MetafileJSON.
+func (self MetafileFormat) Filename() string {
+	if self == "" {
+		return MetafileName
+	}
+	return "boil." + string(self)
+}
+
+// IsMetafileName returns true if name is a file name of a Metafile in any of
+// MetafileFormats.
+func IsMetafileName(name string) bool {
+	for _, format := range MetafileFormats {
+		if strings.EqualFold(name, format.Filename()) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalMetafile parses data in format and returns a new *Metafile or an
+// error. If strict is true fields not defined by Metafile are an error.
+//
+// All formats use the same field names as JSON. YAML and TOML documents are
+// converted to JSON before they are decoded. Numbers and booleans given for
+// string fields, i.e. "version: 1.2" or "default: 8080", are converted to
+// strings as written in YAML and as formatted by Go in TOML.
+func UnmarshalMetafile(data []byte, format MetafileFormat, strict bool) (meta *Metafile, err error) {
+	var metafileType = reflect.TypeOf(Metafile{})
+	switch format {
+	case "", MetafileJSON:
+	case MetafileYAML:
+		var (
+			node yaml.Node
+			doc  any
+		)
+		if err = yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml metafile: %w", err)
+		}
+		retagYAMLStrings(&node, metafileType)
+		if err = node.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml metafile: %w", err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("convert yaml metafile: %w", err)
+		}
+	case MetafileTOML:
+		var doc map[string]any
+		if err = toml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("unmarshal toml metafile: %w", err)
+		}
+		if data, err = json.Marshal(stringifyScalars(doc, metafileType)); err != nil {
+			return nil, fmt.Errorf("convert toml metafile: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported metafile format '%s'", format)
+	}
+	var dec = json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	meta = new(Metafile)
+	if err = dec.Decode(meta); err != nil {
+		return nil, fmt.Errorf("unmarshal metafile: %w", err)
+	}
+	meta.Format = format
+	return
+}
+
+// retagYAMLStrings tags scalar nodes in node that are decoded into string
+// values of a value of type t as strings, so that their text is kept as is.
+func retagYAMLStrings(node *yaml.Node, t reflect.Type) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			retagYAMLStrings(child, t)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if ft := jsonValueType(t, node.Content[i].Value); ft != nil {
+				retagYAMLStrings(node.Content[i+1], ft)
+			}
+		}
+	case yaml.SequenceNode:
+		if et := jsonValueType(t, ""); et != nil {
+			for _, child := range node.Content {
+				retagYAMLStrings(child, et)
+			}
+		}
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!int", "!!float", "!!bool":
+			if isStringType(t) {
+				node.Tag = "!!str"
+			}
+		}
+	}
+}
+
+// stringifyScalars returns v with numbers and booleans that are decoded into
+// string values of a value of type t converted to strings.
+func stringifyScalars(v any, t reflect.Type) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if ft := jsonValueType(t, key); ft != nil {
+				v[key] = stringifyScalars(value, ft)
+			}
+		}
+	case []map[string]any:
+		var out = make([]any, len(v))
+		for i, value := range v {
+			out[i] = value
+		}
+		return stringifyScalars(out, t)
+	case []any:
+		if et := jsonValueType(t, ""); et != nil {
+			for i, value := range v {
+				v[i] = stringifyScalars(value, et)
+			}
+		}
+	case int64, float64, bool:
+		if !isStringType(t) {
+			return v
+		}
+		if f, ok := v.(float64); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return fmt.Sprint(v)
+	}
+	return v
+}
+
+// jsonValueType returns the type of the value under key in a JSON object
+// decoded into a value of type t or the type of elements if t is a slice. It
+// returns nil if the type is not known.
+func jsonValueType(t reflect.Type, key string) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return t.Elem()
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			var field = t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			var name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			if strings.EqualFold(name, key) {
+				return field.Type
+			}
+		}
+	}
+	return nil
+}
+
+// isStringType returns true if t is a string type or a type that also
+// decodes from a JSON string, i.e. GroupMember.
+func isStringType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String ||
+		(t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()))
+}
+
+// MarshalMetafile returns meta encoded in meta.Format or an error.
+//
+// YAML output retains the field order of JSON output and uses literal
+// blocks for multi-line strings.
+func MarshalMetafile(meta *Metafile) (data []byte, err error) {
+	if data, err = json.MarshalIndent(meta, "", "\t"); err != nil {
+		return nil, fmt.Errorf("marshal metafile: %w", err)
+	}
+	switch meta.Format {
+	case "", MetafileJSON:
+		return data, nil
+	case MetafileYAML:
+		var node *yaml.Node
+		if node, err = jsonToYAML(json.NewDecoder(bytes.NewReader(data))); err != nil {
+			return nil, fmt.Errorf("convert metafile to yaml: %w", err)
+		}
+		var buf bytes.Buffer
+		var enc = yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err = enc.Encode(node); err != nil {
+			return nil, fmt.Errorf("marshal yaml metafile: %w", err)
+		}
+		return buf.Bytes(), nil
+	case MetafileTOML:
+		var doc map[string]any
+		if err = json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("convert metafile to toml: %w", err)
+		}
+		var buf bytes.Buffer
+		if err = toml.NewEncoder(&buf).Encode(dropNulls(doc)); err != nil {
+			return nil, fmt.Errorf("marshal toml metafile: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported metafile format '%s'", meta.Format)
+}
+
+// jsonToYAML reads the next JSON value from dec and returns it as a YAML
+// node. Object keys retain their order and null values are omitted.
+func jsonToYAML(dec *json.Decoder) (node *yaml.Node, err error) {
+	var token json.Token
+	if token, err = dec.Token(); err != nil {
+		return
+	}
+	switch v := token.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for dec.More() {
+				var key, value *yaml.Node
+				if key, err = jsonToYAML(dec); err != nil {
+					return
+				}
+				if value, err = jsonToYAML(dec); err != nil {
+					return
+				}
+				if value != nil {
+					node.Content = append(node.Content, key, value)
+				}
+			}
+		case '[':
+			node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for dec.More() {
+				var value *yaml.Node
+				if value, err = jsonToYAML(dec); err != nil {
+					return
+				}
+				if value != nil {
+					node.Content = append(node.Content, value)
+				}
+			}
+		}
+		// Consume the closing delimiter.
+		if _, err = dec.Token(); err != nil {
+			return
+		}
+	case string:
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+		if strings.Contains(v, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+	case bool:
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+	case float64:
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: fmt.Sprint(v)}
+	case nil:
+		return nil, nil
+	default:
+		return nil, errors.New("unexpected json token")
+	}
+	return
+}
+
+// dropNulls removes nil values from maps and slices in v recursively and
+// returns it. TOML has no null value.
+func dropNulls(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if value == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = dropNulls(value)
+		}
+	case []any:
+		var out = v[:0]
+		for _, value := range v {
+			if value != nil {
+				out = append(out, dropNulls(value))
+			}
+		}
+		return out
+	}
+	return v
+}
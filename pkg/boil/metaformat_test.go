@@ -0,0 +1,131 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalMetafile(t *testing.T) {
+
+	var yamlDoc = `
+name: app
+version: 1.10
+prompts:
+  - variable: Port
+    type: int
+    default: 8080
+  - variable: Debug
+    type: bool
+    default: true
+  - variable: Level
+    type: choice
+    choices: [1, 2, 3]
+actions:
+  preExecute:
+    - program: setup
+      arguments: [1, true]
+      environment:
+        LEVEL: 2
+groups:
+  - name: all
+    templates:
+      - template: sub
+        vars:
+          Port: 9090
+`
+
+	var tomlDoc = `
+name = "app"
+version = 1.1
+
+[[prompts]]
+variable = "Port"
+type = "int"
+default = 8080
+
+[[prompts]]
+variable = "Debug"
+type = "bool"
+default = true
+
+[[prompts]]
+variable = "Level"
+type = "choice"
+choices = [1, 2, 3]
+
+[[actions.preExecute]]
+program = "setup"
+arguments = [1, true]
+environment = { LEVEL = 2 }
+
+[[groups]]
+name = "all"
+
+[[groups.templates]]
+template = "sub"
+vars = { Port = 9090 }
+`
+
+	for _, test := range []struct {
+		format  MetafileFormat
+		data    string
+		version string
+	}{
+		{MetafileYAML, yamlDoc, "1.10"},
+		{MetafileTOML, tomlDoc, "1.1"},
+	} {
+		for _, strict := range []bool{false, true} {
+			var meta, err = UnmarshalMetafile([]byte(test.data), test.format, strict)
+			if err != nil {
+				t.Fatalf("%s: %v", test.format, err)
+			}
+			if meta.Version != test.version {
+				t.Errorf("%s: version: got %q, want %q", test.format, meta.Version, test.version)
+			}
+			if len(meta.Prompts) != 3 {
+				t.Fatalf("%s: got %d prompts", test.format, len(meta.Prompts))
+			}
+			if meta.Prompts[0].Default != "8080" || meta.Prompts[1].Default != "true" {
+				t.Errorf("%s: defaults: got %q, %q", test.format, meta.Prompts[0].Default, meta.Prompts[1].Default)
+			}
+			if !reflect.DeepEqual(meta.Prompts[2].Choices, []string{"1", "2", "3"}) {
+				t.Errorf("%s: choices: got %v", test.format, meta.Prompts[2].Choices)
+			}
+			var action = meta.Actions.PreExecute[0]
+			if !reflect.DeepEqual(action.Arguments, []string{"1", "true"}) || action.Environment["LEVEL"] != "2" {
+				t.Errorf("%s: action: got %v, %v", test.format, action.Arguments, action.Environment)
+			}
+			// Variables are not strings and keep their types.
+			if port, ok := meta.Groups[0].Templates[0].Vars["Port"].(float64); !ok || port != 9090 {
+				t.Errorf("%s: member vars: got %#v", test.format, meta.Groups[0].Templates[0].Vars["Port"])
+			}
+		}
+	}
+}
+
+func TestMarshalMetafile(t *testing.T) {
+	for _, format := range MetafileFormats {
+		var meta = &Metafile{
+			Name:    "app",
+			Version: "1.10",
+			Format:  format,
+			Prompts: Prompts{{Variable: "Port", Type: PromptInt, Default: "8080"}},
+			Files:   Entries{{Path: "main.go"}},
+		}
+		var data, err = MarshalMetafile(meta)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		var out *Metafile
+		if out, err = UnmarshalMetafile(data, format, true); err != nil {
+			t.Fatalf("%s: %v\n%s", format, err, data)
+		}
+		if out.Version != meta.Version || out.Prompts[0].Default != "8080" || out.Files[0].Path != "main.go" {
+			t.Errorf("%s: round trip mismatch:\n%s", format, data)
+		}
+	}
+}
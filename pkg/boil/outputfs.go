@@ -0,0 +1,265 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OutputFS is a writable filesystem that Template output is written to.
+//
+// Names are absolute paths in the OS path format.
+//
+// Implementations are DiskFS which writes to the local filesystem, MemoryFS
+// which keeps output in memory and OverlayFS which writes to memory over
+// another OutputFS.
+type OutputFS interface {
+	// Stat returns file info of the file or directory at name or an error.
+	// If the file does not exist the error wraps fs.ErrNotExist.
+	Stat(name string) (fs.FileInfo, error)
+	// ReadFile returns contents of the file at name or an error.
+	ReadFile(name string) ([]byte, error)
+	// WriteFile writes data to the file at name, creating or truncating it.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// MkdirAll creates a directory at name along with any necessary parents.
+	MkdirAll(name string, perm fs.FileMode) error
+}
+
+// DiskFS is an OutputFS that writes to the local filesystem.
+type DiskFS struct{}
+
+// Stat implements OutputFS.Stat.
+func (DiskFS) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+// ReadFile implements OutputFS.ReadFile.
+func (DiskFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+// WriteFile implements OutputFS.WriteFile.
+func (DiskFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+// MkdirAll implements OutputFS.MkdirAll.
+func (DiskFS) MkdirAll(name string, perm fs.FileMode) error { return os.MkdirAll(name, perm) }
+
+// NewMemoryFS returns a new empty *MemoryFS.
+func NewMemoryFS() *MemoryFS {
+	return &MemoryFS{entries: make(map[string]*memoryEntry)}
+}
+
+// MemoryFS is an OutputFS that keeps files and directories in memory.
+//
+// Filesystem roots always exist. Like on disk, a file can be written only if
+// its parent directory exists.
+type MemoryFS struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+// memoryEntry is a file or a directory in a MemoryFS.
+type memoryEntry struct {
+	name    string
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (self *memoryEntry) Name() string       { return self.name }
+func (self *memoryEntry) Size() int64        { return int64(len(self.data)) }
+func (self *memoryEntry) Mode() fs.FileMode  { return self.mode }
+func (self *memoryEntry) ModTime() time.Time { return self.modTime }
+func (self *memoryEntry) IsDir() bool        { return self.mode.IsDir() }
+func (self *memoryEntry) Sys() any           { return nil }
+
+// Stat implements OutputFS.Stat.
+func (self *MemoryFS) Stat(name string) (fs.FileInfo, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	var entry, err = self.get("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ReadFile implements OutputFS.ReadFile.
+func (self *MemoryFS) ReadFile(name string) ([]byte, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	var entry, err = self.get("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return append([]byte(nil), entry.data...), nil
+}
+
+// WriteFile implements OutputFS.WriteFile.
+func (self *MemoryFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	name = filepath.Clean(name)
+	if parent, err := self.get("write", filepath.Dir(name)); err != nil {
+		return err
+	} else if !parent.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: errors.New("not a directory")}
+	}
+	if entry, exists := self.entries[name]; exists && entry.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: errors.New("is a directory")}
+	}
+	self.entries[name] = &memoryEntry{
+		name:    filepath.Base(name),
+		data:    append([]byte(nil), data...),
+		mode:    perm.Perm(),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+// MkdirAll implements OutputFS.MkdirAll.
+func (self *MemoryFS) MkdirAll(name string, perm fs.FileMode) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.mkdirAll(filepath.Clean(name), perm)
+}
+
+// Files returns sorted names of all files in self.
+func (self *MemoryFS) Files() (result []string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	for name, entry := range self.entries {
+		if !entry.IsDir() {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return
+}
+
+// Dirs returns sorted names of all directories in self.
+func (self *MemoryFS) Dirs() (result []string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	for name, entry := range self.entries {
+		if entry.IsDir() {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return
+}
+
+// mkdirAll implements MkdirAll. Caller must hold the lock.
+func (self *MemoryFS) mkdirAll(name string, perm fs.FileMode) error {
+	if isFilesystemRoot(name) {
+		return nil
+	}
+	if entry, exists := self.entries[name]; exists {
+		if !entry.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+		}
+		return nil
+	}
+	if err := self.mkdirAll(filepath.Dir(name), perm); err != nil {
+		return err
+	}
+	self.entries[name] = &memoryEntry{
+		name:    filepath.Base(name),
+		mode:    fs.ModeDir | perm.Perm(),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+// get returns an entry by name or a *fs.PathError with op.
+// Caller must hold the lock.
+func (self *MemoryFS) get(op, name string) (*memoryEntry, error) {
+	if name = filepath.Clean(name); isFilesystemRoot(name) {
+		return &memoryEntry{name: name, mode: fs.ModeDir | os.ModePerm}, nil
+	}
+	if entry, exists := self.entries[name]; exists {
+		return entry, nil
+	}
+	return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// isFilesystemRoot returns true if name is a filesystem root or the
+// current directory.
+func isFilesystemRoot(name string) bool {
+	return filepath.Dir(name) == name
+}
+
+// NewOverlayFS returns a new *OverlayFS over lower.
+func NewOverlayFS(lower OutputFS) *OverlayFS {
+	return &OverlayFS{lower: lower, upper: NewMemoryFS()}
+}
+
+// OverlayFS is an OutputFS that reads from a lower OutputFS and writes to an
+// upper MemoryFS. Files written to the upper layer shadow files in the lower
+// layer and the lower layer is never modified.
+//
+// It can be used to render Template output in memory against an existing
+// output directory, i.e. to inspect what an execution would produce.
+type OverlayFS struct {
+	lower OutputFS
+	upper *MemoryFS
+}
+
+// Lower returns the lower read-only layer.
+func (self *OverlayFS) Lower() OutputFS { return self.lower }
+
+// Upper returns the upper layer that holds all writes.
+func (self *OverlayFS) Upper() *MemoryFS { return self.upper }
+
+// Stat implements OutputFS.Stat.
+func (self *OverlayFS) Stat(name string) (fi fs.FileInfo, err error) {
+	if fi, err = self.upper.Stat(name); err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	return self.lower.Stat(name)
+}
+
+// ReadFile implements OutputFS.ReadFile.
+func (self *OverlayFS) ReadFile(name string) (data []byte, err error) {
+	if data, err = self.upper.ReadFile(name); err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	return self.lower.ReadFile(name)
+}
+
+// WriteFile implements OutputFS.WriteFile.
+// The parent directory must exist in either layer.
+func (self *OverlayFS) WriteFile(name string, data []byte, perm fs.FileMode) (err error) {
+	var (
+		dir = filepath.Dir(name)
+		fi  fs.FileInfo
+	)
+	if fi, err = self.Stat(dir); err != nil {
+		return &fs.PathError{Op: "write", Path: name, Err: err}
+	}
+	if !fi.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: errors.New("not a directory")}
+	}
+	if err = self.upper.MkdirAll(dir, fi.Mode().Perm()); err != nil {
+		return
+	}
+	return self.upper.WriteFile(name, data, perm)
+}
+
+// MkdirAll implements OutputFS.MkdirAll.
+func (self *OverlayFS) MkdirAll(name string, perm fs.FileMode) error {
+	if fi, err := self.lower.Stat(name); err == nil && !fi.IsDir() {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return self.upper.MkdirAll(name, perm)
+}
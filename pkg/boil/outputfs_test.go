@@ -0,0 +1,98 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryFS(t *testing.T) {
+
+	var (
+		mem  = NewMemoryFS()
+		root = filepath.Join(string(filepath.Separator), "out")
+		file = filepath.Join(root, "a", "b.txt")
+	)
+
+	if err := mem.WriteFile(file, []byte("b"), 0644); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("write without parent: got %v, want fs.ErrNotExist", err)
+	}
+	if err := mem.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.WriteFile(file, []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.MkdirAll(file, os.ModePerm); err == nil {
+		t.Fatal("mkdir over a file: expected error")
+	}
+	if err := mem.WriteFile(filepath.Dir(file), nil, 0644); err == nil {
+		t.Fatal("write over a directory: expected error")
+	}
+
+	var data, err = mem.ReadFile(file)
+	if err != nil || string(data) != "b" {
+		t.Fatalf("read: got %q, %v", data, err)
+	}
+	var fi fs.FileInfo
+	if fi, err = mem.Stat(filepath.Dir(file)); err != nil || !fi.IsDir() {
+		t.Fatalf("stat dir: got %v, %v", fi, err)
+	}
+	if _, err = mem.Stat(filepath.Join(root, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("stat missing: got %v, want fs.ErrNotExist", err)
+	}
+
+	if files := mem.Files(); !reflect.DeepEqual(files, []string{file}) {
+		t.Fatalf("files: got %v", files)
+	}
+	if dirs := mem.Dirs(); !reflect.DeepEqual(dirs, []string{root, filepath.Dir(file)}) {
+		t.Fatalf("dirs: got %v", dirs)
+	}
+}
+
+func TestOverlayFS(t *testing.T) {
+
+	var (
+		dir      = t.TempDir()
+		existing = filepath.Join(dir, "existing.txt")
+		created  = filepath.Join(dir, "sub", "created.txt")
+	)
+	if err := os.WriteFile(existing, []byte("lower"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var overlay = NewOverlayFS(DiskFS{})
+	if err := overlay.WriteFile(existing, []byte("upper"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.MkdirAll(filepath.Dir(created), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := overlay.WriteFile(created, []byte("created"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{existing: "upper", created: "created"} {
+		if data, err := overlay.ReadFile(name); err != nil || string(data) != want {
+			t.Fatalf("read %s: got %q, %v, want %q", name, data, err, want)
+		}
+	}
+
+	// Lower layer is never modified.
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "lower" {
+		t.Fatalf("lower layer modified: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Dir(created)); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("lower layer directory created: %v", err)
+	}
+	if files := overlay.Upper().Files(); !reflect.DeepEqual(files, []string{existing, created}) {
+		t.Fatalf("upper files: got %v", files)
+	}
+}
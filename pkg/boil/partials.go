@@ -0,0 +1,124 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// PartialsDir is the name of a directory that contains partials, template
+// files shared by Template files.
+//
+// A partials directory may exist in the Repository root, in any Template
+// directory and in any directory that contains Templates. Files in it are
+// not Template files, they are parsed into the template set of each executed
+// Template file so that templates they define using {{define}} can be used
+// from a Template file with {{template}}. Each partial file also defines a
+// template named by its path relative to the partials directory, in slash
+// format, without the extension.
+//
+// See PartialDirs for the order in which partials override each other.
+const PartialsDir = "_partials"
+
+// IsPartialsDir returns true if path is a partials directory.
+func IsPartialsDir(path string) bool {
+	return filepath.Base(path) == PartialsDir
+}
+
+// PartialDirs returns paths of existing partials directories available to
+// Template files of the Template meta in repo, in order of increasing
+// precedence, or an error.
+//
+// Each Template meta extends, starting with the most distant, and then meta
+// adds partials directories of the directories from the repo root down to
+// its own directory, so "go/_partials" precedes "go/app/_partials" for
+// Template "go/app". A directory added more than once keeps its first
+// position. Partials defined by a directory override partials of the same
+// name defined by directories before it.
+func PartialDirs(repo Repository, meta *Metafile) (dirs []string, err error) {
+	var resolved *Metafile
+	if resolved, err = ResolveExtends(repo, meta); err != nil {
+		return nil, err
+	}
+	var chain = append(append([]string{}, resolved.extended...), meta.Path)
+	return existingPartialDirs(repo, ancestorDirs(chain...)...)
+}
+
+// ancestorDirs returns the repository root "." followed by directories from
+// the root down to each of paths, including the path itself, in order.
+func ancestorDirs(paths ...string) (dirs []string) {
+	dirs = []string{"."}
+	for _, path := range paths {
+		var dir = "."
+		for _, name := range strings.Split(filepath.ToSlash(filepath.Clean(path)), "/") {
+			if name == "." {
+				continue
+			}
+			dir = filepath.Join(dir, name)
+			dirs = append(dirs, dir)
+		}
+	}
+	return
+}
+
+// existingPartialDirs returns paths of partials directories that exist in
+// templateDirs of repo, in order and without duplicates. A duplicate keeps
+// the position of its first occurrence.
+func existingPartialDirs(repo Repository, templateDirs ...string) (dirs []string, err error) {
+	var seen = make(map[string]bool)
+	for _, dir := range templateDirs {
+		var (
+			path   = filepath.Join(dir, PartialsDir)
+			exists bool
+		)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if exists, err = repo.Exists(path); err != nil {
+			return nil, fmt.Errorf("check partials directory %s: %w", path, err)
+		}
+		if exists {
+			dirs = append(dirs, path)
+		}
+	}
+	return
+}
+
+// ParsePartials returns a template set with funcs that contains partials
+// parsed from files in partials directories dirs of repo, in order, or an
+// error. A Template file is added to the set using Clone and New.
+func ParsePartials(repo Repository, dirs []string, funcs template.FuncMap) (out *template.Template, err error) {
+	out = template.New("").Funcs(funcs)
+	for _, dir := range dirs {
+		if err = repo.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			var (
+				data []byte
+				name string
+			)
+			if data, err = repo.ReadFile(path); err != nil {
+				return fmt.Errorf("read partial %s: %w", path, err)
+			}
+			if name, err = filepath.Rel(dir, path); err != nil {
+				return err
+			}
+			name = filepath.ToSlash(strings.TrimSuffix(name, filepath.Ext(name)))
+			if _, err = out.New(name).Parse(string(data)); err != nil {
+				return fmt.Errorf("parse partial %s: %w", path, err)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// testPartials is a repository with partials in the root, in a directory
+// that contains Templates and in Template directories.
+var testPartials = fstest.MapFS{
+	"_partials/license.tmpl":     {Data: []byte(`MIT`)},
+	"_partials/header.tmpl":      {Data: []byte(`root header`)},
+	"go/_partials/header.tmpl":   {Data: []byte(`go header`)},
+	"go/_partials/pkg/name.tmpl": {Data: []byte(`go pkg`)},
+	"go/_partials/module.tmpl":   {Data: []byte(`go module`)},
+	"go/base/boil.json": {Data: []byte(`{
+		"files": [{"path": "base.go"}]
+	}`)},
+	"go/base/base.go":                 {Data: []byte(`{{template "header"}}, {{template "pkg/name"}}`)},
+	"go/base/_partials/pkg/name.tmpl": {Data: []byte(`base pkg`)},
+	"go/app/boil.json": {Data: []byte(`{
+		"extends": "go/base",
+		"files": [{"path": "main.go"}]
+	}`)},
+	"go/app/main.go":               {Data: []byte(`{{template "license"}}, {{template "module"}}, {{template "header"}}, {{template "pkg/name"}}`)},
+	"go/app/_partials/header.tmpl": {Data: []byte(`app header`)},
+}
+
+func TestPartialDirs(t *testing.T) {
+
+	var repo = NewFSRepository(testPartials)
+	for _, test := range []struct {
+		template string
+		dirs     []string
+	}{
+		{"go/base", []string{"_partials", "go/_partials", "go/base/_partials"}},
+		{"go/app", []string{"_partials", "go/_partials", "go/base/_partials", "go/app/_partials"}},
+	} {
+		var meta, err = repo.OpenMeta(test.template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dirs []string
+		if dirs, err = PartialDirs(repo, meta); err != nil {
+			t.Fatal(err)
+		}
+		for i := range test.dirs {
+			test.dirs[i] = filepath.FromSlash(test.dirs[i])
+		}
+		if !reflect.DeepEqual(dirs, test.dirs) {
+			t.Errorf("%s: got %v, want %v", test.template, dirs, test.dirs)
+		}
+	}
+
+	var tasks, err = TasksFromWalk(repo, filepath.FromSlash("go/app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want = []string{"_partials", filepath.FromSlash("go/_partials"), filepath.FromSlash("go/app/_partials")}
+	if !reflect.DeepEqual(tasks[0].Partials, want) {
+		t.Errorf("walk: got %v, want %v", tasks[0].Partials, want)
+	}
+}
+
+func TestExecutorPartials(t *testing.T) {
+
+	var (
+		args         []string
+		exec, output = newTestExecutor(testPartials, nil, &args)
+		out          = filepath.Join(string(filepath.Separator), "out")
+	)
+	if err := exec.Execute("go/app", out); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		filepath.Join(out, "main.go"): "MIT, go module, app header, base pkg",
+		filepath.Join(out, "base.go"): "app header, base pkg",
+	} {
+		if data, err := output.ReadFile(name); err != nil || string(data) != want {
+			t.Errorf("%s: got %q, %v, want %q", name, data, err, want)
+		}
+	}
+}
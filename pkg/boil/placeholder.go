@@ -0,0 +1,143 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PathFilters maps names of filters that may follow a placeholder in a path
+// to functions that transform the placeholder value. See ExpandPath.
+// Filters are template functions as well, see FuncMap.
+var PathFilters = map[string]func(string) string{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"title":      titleCase,
+	"snake":      snakeCase,
+	"screaming":  screamingSnakeCase,
+	"kebab":      kebabCase,
+	"camel":      camelCase,
+	"pascal":     pascalCase,
+	"plural":     pluralize,
+	"singular":   singularize,
+	"goIdent":    goIdent,
+	"exported":   exported,
+	"unexported": unexported,
+}
+
+// PathFilterNames returns sorted names of PathFilters.
+func PathFilterNames() (names []string) {
+	for name := range PathFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// invalidPathChars are characters not allowed in values expanded into paths
+// because they are not valid in file names on all platforms.
+const invalidPathChars = `\<>:"|?*`
+
+// expandPlaceholders returns in with each placeholder replaced by the result
+// of expand called with the placeholder variable name and filter names.
+//
+// A placeholder is a "$" followed by a variable name, optionally followed by
+// filters, each a "|" followed by a filter name, i.e. "$ProjectName|snake".
+// A placeholder can be enclosed in braces to separate it from text that
+// follows, i.e. "${Name|lower}_test.go". "$$" is a literal "$" and a "$"
+// not followed by a name or a brace is kept as is.
+//
+// If a name not enclosed in braces is not a variable as reported by known,
+// the longest variable name that prefixes it is used and the rest of the
+// name is kept as text, so "$Name_test" expands "Name". Filters are then not
+// recognized. If no variable prefixes the name expand is called with the
+// whole name.
+func expandPlaceholders(in string, known func(name string) bool, expand func(name string, filters []string) (string, error)) (string, error) {
+	var (
+		out   strings.Builder
+		runes = []rune(in)
+	)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '$' || i+1 == len(runes) {
+			out.WriteRune(runes[i])
+			continue
+		}
+		switch next := runes[i+1]; {
+		case next == '$':
+			out.WriteRune('$')
+			i++
+		case next == '{':
+			var end = i + 2
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end == len(runes) {
+				return "", fmt.Errorf("unterminated placeholder in '%s'", in)
+			}
+			var fields = strings.Split(string(runes[i+2:end]), "|")
+			var value, err = expand(fields[0], fields[1:])
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(value)
+			i = end
+		case next == '_' || unicode.IsLetter(next):
+			var end = i + 1
+			for end < len(runes) && isNameRune(runes[end]) {
+				end++
+			}
+			var (
+				name = string(runes[i+1 : end])
+				rest string
+			)
+			if !known(name) {
+				for n := len(name) - 1; n > 0; n-- {
+					if known(name[:n]) {
+						name, rest = name[:n], name[n:]
+						break
+					}
+				}
+			}
+			var filters []string
+			for rest == "" && end+1 < len(runes) && runes[end] == '|' && isNameRune(runes[end+1]) {
+				var start = end + 1
+				for end = start; end < len(runes) && isNameRune(runes[end]); end++ {
+				}
+				filters = append(filters, string(runes[start:end]))
+			}
+			var value, err = expand(name, filters)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(value + rest)
+			i = end - 1
+		default:
+			out.WriteRune('$')
+		}
+	}
+	return out.String(), nil
+}
+
+// isNameRune returns true if r may be a part of a variable or filter name.
+func isNameRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// applyPathFilters returns value transformed by PathFilters named by
+// filters, in order, or an error if a filter does not exist.
+func applyPathFilters(value string, filters []string) (string, error) {
+	for _, name := range filters {
+		var filter, exists = PathFilters[name]
+		if !exists {
+			return "", fmt.Errorf("unknown filter '%s', must be one of: %s",
+				name, strings.Join(PathFilterNames(), ", "))
+		}
+		value = filter(value)
+	}
+	return value, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PromptType is the type of a Prompt value.
+type PromptType string
+
+const (
+	// PromptString asks for a string. Value type is string.
+	PromptString PromptType = "string"
+	// PromptBool asks for a yes or no answer. Value type is bool.
+	PromptBool PromptType = "bool"
+	// PromptInt asks for an integer. Value type is int.
+	PromptInt PromptType = "int"
+	// PromptChoice asks for one of Prompt Choices. Value type is string.
+	PromptChoice PromptType = "choice"
+	// PromptMultiChoice asks for zero or more of Prompt Choices.
+	// Value type is []string.
+	PromptMultiChoice PromptType = "multi-choice"
+	// PromptList asks for a list of strings. Value type is []string.
+	PromptList PromptType = "list"
+)
+
+// PromptTypes lists all valid PromptType values.
+var PromptTypes = []PromptType{
+	PromptString,
+	PromptBool,
+	PromptInt,
+	PromptChoice,
+	PromptMultiChoice,
+	PromptList,
+}
+
+// GetType returns the prompt Type or PromptString if Type is empty.
+func (self *Prompt) GetType() PromptType {
+	if self.Type == "" {
+		return PromptString
+	}
+	return self.Type
+}
+
+// ParseValue parses a value of the prompt type from string s and returns it
+// or an error. Values of list types are comma separated. Parsed values are
+// not checked, see CheckValue.
+func (self *Prompt) ParseValue(s string) (value any, err error) {
+	switch self.GetType() {
+	case PromptString, PromptChoice:
+		return s, nil
+	case PromptBool:
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "yes", "y":
+			return true, nil
+		case "no", "n", "":
+			return false, nil
+		}
+		if value, err = strconv.ParseBool(strings.TrimSpace(s)); err != nil {
+			return nil, fmt.Errorf("variable '%s': invalid boolean '%s'", self.Variable, s)
+		}
+		return
+	case PromptInt:
+		if value, err = strconv.Atoi(strings.TrimSpace(s)); err != nil {
+			return nil, fmt.Errorf("variable '%s': invalid integer '%s'", self.Variable, s)
+		}
+		return
+	case PromptMultiChoice, PromptList:
+		var list = []string{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("variable '%s': invalid prompt type '%s'", self.Variable, self.Type)
+}
+
+// ConvertValue converts value to the prompt type and returns it or an error.
+//
+// Strings are parsed using ParseValue. Values decoded from JSON, i.e. float64
+// numbers and []any lists, are converted to their prompt types.
+func (self *Prompt) ConvertValue(value any) (any, error) {
+	if s, ok := value.(string); ok {
+		return self.ParseValue(s)
+	}
+	switch self.GetType() {
+	case PromptString, PromptChoice:
+		return fmt.Sprint(value), nil
+	case PromptBool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case PromptInt:
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) {
+				return int(v), nil
+			}
+		}
+	case PromptMultiChoice, PromptList:
+		switch v := value.(type) {
+		case []string:
+			return v, nil
+		case []any:
+			var list = make([]string, 0, len(v))
+			for _, item := range v {
+				list = append(list, fmt.Sprint(item))
+			}
+			return list, nil
+		}
+	default:
+		return nil, fmt.Errorf("variable '%s': invalid prompt type '%s'", self.Variable, self.Type)
+	}
+	return nil, fmt.Errorf("variable '%s': invalid %s value '%v'", self.Variable, self.GetType(), value)
+}
+
+// CheckValue returns nil if value is a valid value for the prompt or an
+// error describing why it is not.
+//
+// Empty strings and lists are invalid unless the prompt is Optional, strings
+// must match RegExp if set and choices must be one of Choices.
+func (self *Prompt) CheckValue(value any) (err error) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			if !self.Optional {
+				return fmt.Errorf("variable '%s' may not have an empty value", self.Variable)
+			}
+			return nil
+		}
+		if self.RegExp != "" {
+			var match bool
+			if match, err = regexp.MatchString(self.RegExp, v); err != nil {
+				return fmt.Errorf("prompt '%s' regexp: %w", self.Variable, err)
+			}
+			if !match {
+				return fmt.Errorf("invalid value for variable '%s': %s", self.Variable, v)
+			}
+		}
+		if self.GetType() == PromptChoice && !self.isChoice(v) {
+			return fmt.Errorf("invalid choice for variable '%s': %s", self.Variable, v)
+		}
+	case []string:
+		if len(v) == 0 && !self.Optional {
+			return fmt.Errorf("variable '%s' may not have an empty value", self.Variable)
+		}
+		if self.GetType() == PromptMultiChoice {
+			for _, item := range v {
+				if !self.isChoice(item) {
+					return fmt.Errorf("invalid choice for variable '%s': %s", self.Variable, item)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isChoice returns true if s is one of self.Choices.
+func (self *Prompt) isChoice(s string) bool {
+	for _, choice := range self.Choices {
+		if choice == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPromptParseValue(t *testing.T) {
+	for _, test := range []struct {
+		typ   PromptType
+		in    string
+		value any
+		err   bool
+	}{
+		{"", "foo", "foo", false},
+		{PromptString, " foo ", " foo ", false},
+		{PromptChoice, "a", "a", false},
+		{PromptBool, "yes", true, false},
+		{PromptBool, "Y", true, false},
+		{PromptBool, "true", true, false},
+		{PromptBool, "no", false, false},
+		{PromptBool, "", false, false},
+		{PromptBool, "0", false, false},
+		{PromptBool, "maybe", nil, true},
+		{PromptInt, " 8080 ", 8080, false},
+		{PromptInt, "-1", -1, false},
+		{PromptInt, "1.5", nil, true},
+		{PromptList, "a, b,,c ", []string{"a", "b", "c"}, false},
+		{PromptList, "", []string{}, false},
+		{PromptMultiChoice, "a,b", []string{"a", "b"}, false},
+		{"float", "1", nil, true},
+	} {
+		var prompt = &Prompt{Variable: "V", Type: test.typ}
+		var value, err = prompt.ParseValue(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("%s %q: unexpected error %v", test.typ, test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, test.value) {
+			t.Errorf("%s %q: got %#v, want %#v", test.typ, test.in, value, test.value)
+		}
+	}
+}
+
+func TestPromptConvertValue(t *testing.T) {
+	for _, test := range []struct {
+		typ   PromptType
+		in    any
+		value any
+		err   bool
+	}{
+		{PromptString, "foo", "foo", false},
+		{PromptString, 42.0, "42", false},
+		{PromptString, true, "true", false},
+		{PromptChoice, 2.0, "2", false},
+		{PromptBool, true, true, false},
+		{PromptBool, "y", true, false},
+		{PromptBool, 1.0, nil, true},
+		{PromptInt, 8080.0, 8080, false},
+		{PromptInt, 8080, 8080, false},
+		{PromptInt, "8080", 8080, false},
+		{PromptInt, 1.5, nil, true},
+		{PromptList, []any{"a", 1.0, true}, []string{"a", "1", "true"}, false},
+		{PromptList, []string{"a"}, []string{"a"}, false},
+		{PromptMultiChoice, "a,b", []string{"a", "b"}, false},
+		{PromptList, 1.0, nil, true},
+		{"float", 1.0, nil, true},
+	} {
+		var prompt = &Prompt{Variable: "V", Type: test.typ}
+		var value, err = prompt.ConvertValue(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("%s %#v: unexpected error %v", test.typ, test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, test.value) {
+			t.Errorf("%s %#v: got %#v, want %#v", test.typ, test.in, value, test.value)
+		}
+	}
+}
+
+func TestPromptCheckValue(t *testing.T) {
+	for _, test := range []struct {
+		prompt Prompt
+		value  any
+		ok     bool
+	}{
+		{Prompt{}, "foo", true},
+		{Prompt{}, "", false},
+		{Prompt{Optional: true}, "", true},
+		{Prompt{RegExp: "^[a-z]+$"}, "foo", true},
+		{Prompt{RegExp: "^[a-z]+$"}, "Foo", false},
+		{Prompt{RegExp: "("}, "foo", false},
+		{Prompt{Type: PromptChoice, Choices: []string{"a", "b"}}, "b", true},
+		{Prompt{Type: PromptChoice, Choices: []string{"a", "b"}}, "c", false},
+		{Prompt{Type: PromptMultiChoice, Choices: []string{"a", "b"}}, []string{"a", "b"}, true},
+		{Prompt{Type: PromptMultiChoice, Choices: []string{"a", "b"}}, []string{"a", "c"}, false},
+		{Prompt{Type: PromptList}, []string{}, false},
+		{Prompt{Type: PromptList, Optional: true}, []string{}, true},
+		{Prompt{Type: PromptBool}, false, true},
+		{Prompt{Type: PromptInt}, 0, true},
+	} {
+		test.prompt.Variable = "V"
+		if err := test.prompt.CheckValue(test.value); (err == nil) != test.ok {
+			t.Errorf("%+v %#v: got %v, want ok %t", test.prompt, test.value, err, test.ok)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Prompter answers Prompts during Template execution.
+type Prompter interface {
+	// Prompt returns a value for prompt defined by the Template at
+	// templatePath or an error. def is the suggested default value in the
+	// format of Prompt.Default.
+	//
+	// The returned value should be of the type for the prompt type, see
+	// PromptType. String values are converted using Prompt.ParseValue.
+	Prompt(templatePath string, prompt *Prompt, def string) (any, error)
+}
+
+// PrompterFunc is a function that implements Prompter.
+type PrompterFunc func(templatePath string, prompt *Prompt, def string) (any, error)
+
+// Prompt implements Prompter.Prompt by calling self.
+func (self PrompterFunc) Prompt(templatePath string, prompt *Prompt, def string) (any, error) {
+	return self(templatePath, prompt, def)
+}
+
+// NewInterrogatorPrompter returns a new *InterrogatorPrompter that reads
+// answers from r and writes prompts to w.
+func NewInterrogatorPrompter(r io.Reader, w io.Writer) *InterrogatorPrompter {
+	return &InterrogatorPrompter{NewInterrogator(r, w)}
+}
+
+// InterrogatorPrompter is a Prompter that asks the user for values using an
+// Interrogator. It is also a ConflictResolver that asks the user how to
+// handle existing output files.
+type InterrogatorPrompter struct {
+	*Interrogator
+}
+
+// Prompt implements Prompter.Prompt.
+//
+// The user is asked for a value using the Interrogator method that matches
+// the prompt type. Input is validated using Prompt.CheckValue and the prompt
+// is repeated if the value is invalid.
+func (self *InterrogatorPrompter) Prompt(templatePath string, prompt *Prompt, def string) (value any, err error) {
+	var title = fmt.Sprintf("%s %s (%s)", templatePath, prompt.Variable, prompt.Description)
+	for {
+		switch prompt.GetType() {
+		case PromptString:
+			var input string
+			if input, err = self.AskValue(title, def, prompt.RegExp); err != nil {
+				return nil, err
+			}
+			value = strings.TrimSpace(input)
+		case PromptInt:
+			var input string
+			if input, err = self.AskValue(title, def, `^\s*-?[0-9]+\s*$`); err != nil {
+				return nil, err
+			}
+			if value, err = prompt.ParseValue(input); err != nil {
+				return nil, err
+			}
+		case PromptBool:
+			// An invalid default is reported by validation, ask with "no".
+			var parsed, _ = prompt.ParseValue(def)
+			var b, _ = parsed.(bool)
+			self.Printf("%s\n", title)
+			if value, err = self.AskYesNo(b); err != nil {
+				return nil, err
+			}
+		case PromptChoice:
+			self.Printf("%s\n", title)
+			if value, err = self.AskChoice(def, prompt.Choices...); err != nil {
+				return nil, err
+			}
+		case PromptMultiChoice:
+			var list []string
+			self.Printf("%s\n", title)
+			if list, err = self.AskMultiChoice(def, prompt.Choices...); err != nil {
+				return nil, err
+			}
+			value = append([]string{}, list...)
+		case PromptList:
+			var list []string
+			self.Printf("%s\n", title)
+			if list, err = self.AskList(); err != nil {
+				return nil, err
+			}
+			if len(list) == 0 && def != "" {
+				return prompt.ParseValue(def)
+			}
+			value = append([]string{}, list...)
+		default:
+			return nil, fmt.Errorf("variable '%s': invalid prompt type '%s'", prompt.Variable, prompt.Type)
+		}
+		if err = prompt.CheckValue(value); err != nil {
+			self.Printf("%s.\n", capitalize(err.Error()))
+			continue
+		}
+		return
+	}
+}
+
+// capitalize returns s with the first letter in upper case.
+func capitalize(s string) string {
+	var r, size = utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// ResolveConflict implements ConflictResolver.ResolveConflict.
+//
+// The user chooses a policy for the existing target file and may choose to
+// view a diff of changes first.
+func (self *InterrogatorPrompter) ResolveConflict(target string, current, rendered []byte) (policy ConflictPolicy, err error) {
+	var choice string
+	for {
+		self.Printf("File %s already exists.\n", target)
+		if choice, err = self.AskChoice(string(ConflictSkip),
+			"skip\tKeep the existing file.",
+			"overwrite\tOverwrite the existing file.",
+			"append\tAppend output to the existing file.",
+			"boilnew\tWrite output to a "+SideFileExt+" file.",
+			"merge\tMerge output into the existing file.",
+			"diff\tShow differences.",
+		); err != nil {
+			return
+		}
+		if choice != "diff" {
+			return ParseConflictPolicy(choice)
+		}
+		var buf bytes.Buffer
+		if err = WriteUnifiedDiff(&buf, target, target, current, rendered); err != nil {
+			return
+		}
+		self.Printf("%s", buf.String())
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterrogatorPrompter(t *testing.T) {
+	for _, test := range []struct {
+		prompt Prompt
+		def    string
+		input  string
+		value  any
+	}{
+		{Prompt{Type: PromptString}, "foo", "\n", "foo"},
+		{Prompt{Type: PromptString}, "foo", "bar\n", "bar"},
+		{Prompt{Type: PromptInt}, "8080", "\n", 8080},
+		{Prompt{Type: PromptInt}, "8080", "x\n80\n", 80},
+		{Prompt{Type: PromptBool}, "yes", "\n", true},
+		{Prompt{Type: PromptBool}, "y", "\n", true},
+		{Prompt{Type: PromptBool}, "true", "\n", true},
+		{Prompt{Type: PromptBool}, "no", "\n", false},
+		{Prompt{Type: PromptBool}, "", "\n", false},
+		{Prompt{Type: PromptBool}, "no", "yes\n", true},
+		{Prompt{Type: PromptList}, "a,b", "\n", []string{"a", "b"}},
+		{Prompt{Type: PromptList}, "", "c\nd\n\n", []string{"c", "d"}},
+	} {
+		test.prompt.Variable = "V"
+		var (
+			prompter   = NewInterrogatorPrompter(strings.NewReader(test.input), io.Discard)
+			value, err = prompter.Prompt("app", &test.prompt, test.def)
+		)
+		if err != nil {
+			t.Errorf("%s %q: %v", test.prompt.Type, test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, test.value) {
+			t.Errorf("%s default %q input %q: got %#v, want %#v", test.prompt.Type, test.def, test.input, value, test.value)
+		}
+	}
+
+	// The default of a bool prompt is shown as the default answer.
+	var out bytes.Buffer
+	var prompter = NewInterrogatorPrompter(strings.NewReader("\n"), &out)
+	if _, err := prompter.Prompt("app", &Prompt{Variable: "V", Type: PromptBool}, "yes"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "[yes]") {
+		t.Errorf("default not shown: %q", out.String())
+	}
+}
@@ -13,20 +13,37 @@ import (
 	"strings"
 )
 
+// ErrTemplateNotFound is returned when a template is not found in any of the
+// repositories in the search path.
+var ErrTemplateNotFound = errors.New("template not found")
+
 // OpenRepository opens a repository at the specified path. It returns an
 // implementation that handles the specific path format.
 //
 // Currently supported backends:
 // * local filesystem (DiskRepository)
+// * git repository (GitRepository), see IsGitRepositoryPath.
+// * zip or tar.gz archive (ArchiveRepository), see IsArchivePath.
 //
 // If an error occurs it is returned with a nil repository.
 func OpenRepository(path string) (repo Repository, err error) {
 
-	// TODO: Detect repository path and return an appropriate implementaiton.
-
-	// TODO: Implement network loading.
+	if IsArchivePath(path) {
+		var archive *ArchiveRepository
+		if archive, err = OpenArchiveRepository(path); err != nil {
+			return nil, err
+		}
+		return archive, nil
+	}
+	if IsGitRepositoryPath(path) {
+		var git *GitRepository
+		if git, err = OpenGitRepository(path); err != nil {
+			return nil, err
+		}
+		return git, nil
+	}
 	if strings.HasPrefix(strings.ToLower(path), "http") {
-		return nil, errors.New("loading repositories from network not yet implemented")
+		return nil, fmt.Errorf("loading repositories over http is supported only for git repositories, use '%s%s'", GitPrefix, path)
 	}
 
 	if err = os.MkdirAll(path, os.ModePerm); err != nil {
@@ -109,8 +126,145 @@ type Repository interface {
 	WalkDir(root string, f fs.WalkDirFunc) error
 }
 
+// StrictRepository is a Repository that can load Metafiles in strict mode.
+// In strict mode a Metafile that contains fields not defined by Metafile, i.e.
+// a misspelled field name, fails to load.
+//
+// DiskRepository and FSRepository and repositories based on them implement
+// StrictRepository.
+type StrictRepository interface {
+	Repository
+	// SetStrict enables or disables strict mode.
+	SetStrict(strict bool)
+}
+
 // IsRepoPath returns truth is the path is a path relative to repository.
 // This is true if the path has no relation prefix and is not rooted.
 func IsRepoPath(in string) bool {
 	return !strings.HasPrefix(in, ".") && !strings.HasPrefix(in, "/")
 }
+
+// OpenRepositories returns the repositories in the search path defined by
+// config, in order of precedence. See Config.GetRepositories. Repositories
+// that support it load Metafiles in strict mode if config.IsStrict().
+//
+// Repositories are opened lazily, when first searched by Resolve or
+// ResolveDir or explicitly using NamedRepository.Open, so that commands do
+// not open, i.e. fetch, repositories they do not use.
+//
+// If an error occurs it is returned with nil repositories.
+func OpenRepositories(config *Config) (repos Repositories, err error) {
+	for _, def := range config.GetRepositories() {
+		if def.Name == "" {
+			return nil, fmt.Errorf("repository '%s' has no name", def.Path)
+		}
+		if repos.Find(def.Name) != nil {
+			return nil, fmt.Errorf("duplicate repository name '%s'", def.Name)
+		}
+		repos = append(repos, &NamedRepository{
+			Name:   def.Name,
+			Path:   def.Path,
+			strict: config.IsStrict(),
+		})
+	}
+	return
+}
+
+// NamedRepository is a Repository with a name in a repository search path.
+//
+// The embedded Repository is nil until the repository is opened, see Open.
+type NamedRepository struct {
+	// Name is the repository name.
+	Name string
+	// Path is the repository path as given to OpenRepository.
+	Path string
+	Repository
+	// strict is the strict mode to set on the opened Repository.
+	strict bool
+}
+
+// Open opens the repository at Path if it is not open already and returns
+// nil or an error.
+func (self *NamedRepository) Open() (err error) {
+	if self.Repository != nil {
+		return nil
+	}
+	var repo Repository
+	if repo, err = OpenRepository(self.Path); err != nil {
+		return fmt.Errorf("open repository '%s': %w", self.Name, err)
+	}
+	if strict, ok := repo.(StrictRepository); ok {
+		strict.SetStrict(self.strict)
+	}
+	self.Repository = repo
+	return nil
+}
+
+// Repositories is an ordered repository search path, most important first.
+type Repositories []*NamedRepository
+
+// Find returns a repository by name or nil if not found.
+func (self Repositories) Find(name string) *NamedRepository {
+	for _, repo := range self {
+		if repo.Name == name {
+			return repo
+		}
+	}
+	return nil
+}
+
+// Resolve resolves a template path against repositories in self, opening
+// them in order as they are searched.
+//
+// If path is prefixed with a name of a repository in self followed by a colon
+// i.e. "team:apps/app" the template is looked up only in that repository.
+// Otherwise the template is looked up in each repository in order and the
+// first repository that contains it is returned.
+//
+// It returns the repository that contains the template and the template path
+// inside that repository, without repository prefix but with the group suffix
+// if one was specified. If the template is not found ErrTemplateNotFound is
+// returned.
+func (self Repositories) Resolve(path string) (repo *NamedRepository, tmplPath string, err error) {
+	return self.resolve(path, func(repo Repository, dir string) (bool, error) {
+		return repo.HasMeta(dir)
+	})
+}
+
+// ResolveDir is like Resolve but looks for a directory at path instead of a
+// template, i.e. a directory that need not contain a Metafile.
+func (self Repositories) ResolveDir(path string) (repo *NamedRepository, tmplPath string, err error) {
+	return self.resolve(path, func(repo Repository, dir string) (bool, error) {
+		return repo.Exists(dir)
+	})
+}
+
+// resolve implements Resolve and ResolveDir using exists to check if the
+// path exists in a repository.
+func (self Repositories) resolve(path string, exists func(Repository, string) (bool, error)) (repo *NamedRepository, tmplPath string, err error) {
+
+	var search = self
+	if name, rest, found := strings.Cut(path, ":"); found {
+		if forced := self.Find(name); forced != nil {
+			search, path = Repositories{forced}, rest
+		}
+	}
+
+	var (
+		dir, _, _ = strings.Cut(path, "#")
+		found     bool
+	)
+	for _, repo = range search {
+		if err = repo.Open(); err != nil {
+			return nil, "", err
+		}
+		if found, err = exists(repo.Repository, dir); err != nil {
+			return nil, "", fmt.Errorf("repository '%s': %w", repo.Name, err)
+		}
+		if found {
+			return repo, path, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("%w: %s", ErrTemplateNotFound, path)
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrg/xdg"
+)
+
+func TestRepositoriesResolve(t *testing.T) {
+
+	var cache = xdg.CacheHome
+	xdg.CacheHome = t.TempDir()
+	defer func() { xdg.CacheHome = cache }()
+
+	var first, second = t.TempDir(), t.TempDir()
+	for _, dir := range []string{
+		filepath.Join(first, "apps", "app"),
+		filepath.Join(second, "apps", "app"),
+		filepath.Join(second, "apps", "other"),
+	} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, MetafileName), []byte(`{}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var config = &Config{
+		RepositoryPath: first,
+		Repositories: []*RepositoryDefinition{
+			{Name: "second", Path: second},
+			// Fails to open, must not be opened unless searched.
+			{Name: "broken", Path: GitPrefix + "file://" + filepath.ToSlash(filepath.Join(first, "missing.git"))},
+		},
+	}
+	var repos, err = OpenRepositories(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path, repo, tmplPath string
+	}{
+		{"apps/app", DefaultRepositoryName, "apps/app"},
+		{"apps/app#group", DefaultRepositoryName, "apps/app#group"},
+		{"second:apps/app", "second", "apps/app"},
+		{"apps/other", "second", "apps/other"},
+	} {
+		var repo, tmplPath, err = repos.Resolve(test.path)
+		if err != nil {
+			t.Fatalf("Resolve(%q): %v", test.path, err)
+		}
+		if repo.Name != test.repo || tmplPath != test.tmplPath {
+			t.Errorf("Resolve(%q) = %s, %q, want %s, %q", test.path, repo.Name, tmplPath, test.repo, test.tmplPath)
+		}
+	}
+	if repos.Find("broken").Repository != nil {
+		t.Fatal("repository opened before it was searched")
+	}
+
+	if _, _, err = repos.Resolve("second:apps/missing"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected ErrTemplateNotFound, got %v", err)
+	}
+	if _, _, err = repos.Resolve("apps/missing"); err == nil || errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected open error of broken repository, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Requirement is a parsed Metafile.Requires entry.
+type Requirement struct {
+	// Path is the path of the required Template relative to repository root.
+	Path string
+	// Constraint is the constraint the required Template Version must
+	// satisfy. It is empty if any version is acceptable.
+	Constraint VersionConstraint
+}
+
+// ParseRequirement returns a Requirement parsed from s or an error.
+// s is a Template path optionally followed by whitespace and a version
+// constraint, i.e. "go/utils/stringer >=1.2". See ParseVersionConstraint.
+func ParseRequirement(s string) (out Requirement, err error) {
+	var fields = strings.Fields(s)
+	if len(fields) == 0 {
+		return out, fmt.Errorf("empty requirement")
+	}
+	if !isChildPath(fields[0]) {
+		return out, fmt.Errorf("invalid required template path '%s'", fields[0])
+	}
+	out.Path = path.Clean(fields[0])
+	if out.Constraint, err = ParseVersionConstraint(strings.Join(fields[1:], " ")); err != nil {
+		return out, err
+	}
+	return
+}
+
+// Check returns nil if the Version of meta satisfies the Constraint of self
+// or an error describing why it does not.
+func (self Requirement) Check(meta *Metafile) (err error) {
+	if len(self.Constraint) == 0 {
+		return nil
+	}
+	var version SemVer
+	if version, err = ParseSemVer(meta.Version); err != nil {
+		return fmt.Errorf("required template %s: %w", self.Path, err)
+	}
+	if !self.Constraint.Check(version) {
+		return fmt.Errorf("required template %s version %s does not satisfy '%s'",
+			self.Path, version, self.Constraint)
+	}
+	return nil
+}
+
+// requirementPath returns the Template path of a Metafile.Requires entry s.
+func requirementPath(s string) string {
+	var fields = strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	return path.Clean(fields[0])
+}
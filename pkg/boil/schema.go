@@ -0,0 +1,184 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaDraft is the JSON Schema dialect of generated schemas.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document.
+type Schema map[string]any
+
+// MetafileSchema returns the JSON Schema of a Metafile.
+func MetafileSchema() Schema {
+	return GenerateSchema("Boil metafile", reflect.TypeOf(Metafile{}))
+}
+
+// ConfigSchema returns the JSON Schema of a Config.
+func ConfigSchema() Schema {
+	return GenerateSchema("Boil configuration", reflect.TypeOf(Config{}))
+}
+
+// GenerateSchema generates a JSON Schema for values of type t as they are
+// marshaled by encoding/json and returns it.
+//
+// Named struct types are defined once under "$defs" and referenced. Objects
+// do not allow properties that do not map to struct fields. Types that
+// implement JSONSchemer define their own schemas.
+func GenerateSchema(title string, t reflect.Type) Schema {
+	var g = &schemaGenerator{defs: make(map[string]any)}
+	var root = Schema{
+		"$schema": SchemaDraft,
+		"title":   title,
+	}
+	for k, v := range g.object(t) {
+		root[k] = v
+	}
+	if len(g.defs) > 0 {
+		root["$defs"] = g.defs
+	}
+	return root
+}
+
+// JSONSchemer is implemented by types that define their own JSON Schema
+// because they are not marshaled as their underlying type.
+type JSONSchemer interface {
+	// JSONSchema returns the JSON Schema of the type.
+	JSONSchema() Schema
+}
+
+// MarshalIndent returns self as indented JSON.
+func (self Schema) MarshalIndent() ([]byte, error) {
+	return json.MarshalIndent(self, "", "\t")
+}
+
+// schemaGenerator generates schemas and collects definitions of named types.
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+var jsonSchemerType = reflect.TypeOf((*JSONSchemer)(nil)).Elem()
+
+// schema returns the schema of type t.
+func (self *schemaGenerator) schema(t reflect.Type) Schema {
+	if t.Kind() == reflect.Pointer {
+		return self.schema(t.Elem())
+	}
+	if reflect.PointerTo(t).Implements(jsonSchemerType) {
+		return reflect.New(t).Interface().(JSONSchemer).JSONSchema()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": self.schema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": self.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return self.object(t)
+		}
+		if _, exists := self.defs[t.Name()]; !exists {
+			// Reserve the name first in case the type is recursive.
+			self.defs[t.Name()] = nil
+			self.defs[t.Name()] = self.object(t)
+		}
+		return Schema{"$ref": "#/$defs/" + t.Name()}
+	}
+	return Schema{}
+}
+
+// object returns the schema of struct type t.
+func (self *schemaGenerator) object(t reflect.Type) Schema {
+	var props = make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		var field = t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		var name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		props[name] = self.schema(field.Type)
+	}
+	return Schema{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+// JSONSchema implements JSONSchemer.
+func (self Entry) JSONSchema() Schema {
+	return Schema{
+		"oneOf": []any{
+			Schema{"type": "string"},
+			Schema{
+				"type": "object",
+				"properties": map[string]any{
+					"path": Schema{"type": "string"},
+					"when": Schema{"type": "string"},
+				},
+				"required":             []string{"path"},
+				"additionalProperties": false,
+			},
+		},
+	}
+}
+
+// JSONSchema implements JSONSchemer.
+func (self GroupMember) JSONSchema() Schema {
+	return Schema{
+		"oneOf": []any{
+			Schema{"type": "string"},
+			Schema{
+				"type": "object",
+				"properties": map[string]any{
+					"template":    Schema{"type": "string"},
+					"description": Schema{"type": "string"},
+					"optional":    Schema{"type": "boolean"},
+					"selected":    Schema{"type": "boolean"},
+					"vars":        Schema{"type": "object"},
+				},
+				"required":             []string{"template"},
+				"additionalProperties": false,
+			},
+		},
+	}
+}
+
+// JSONSchema implements JSONSchemer.
+func (self PromptType) JSONSchema() Schema {
+	var types []string
+	for _, t := range PromptTypes {
+		types = append(types, string(t))
+	}
+	return Schema{"type": "string", "enum": types}
+}
+
+// JSONSchema implements JSONSchemer.
+func (self ConflictPolicy) JSONSchema() Schema {
+	var policies []string
+	for _, policy := range ConflictPolicies {
+		policies = append(policies, string(policy))
+	}
+	return Schema{"type": "string", "enum": policies}
+}
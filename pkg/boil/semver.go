@@ -0,0 +1,254 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a semantic version, see https://semver.org.
+type SemVer struct {
+	// Major, Minor and Patch are the version numbers.
+	Major, Minor, Patch int
+	// Prerelease is the optional dot separated pre-release identifier
+	// without the leading "-".
+	Prerelease string
+}
+
+// ParseSemVer returns a SemVer parsed from s or an error.
+//
+// A "v" prefix is allowed, minor and patch numbers may be omitted in which
+// case they are zero and build metadata following a "+" is ignored.
+func ParseSemVer(s string) (SemVer, error) {
+	var v, _, err = parseSemVer(s)
+	return v, err
+}
+
+// parseSemVer parses s like ParseSemVer and also returns the number of
+// version numbers given in s.
+func parseSemVer(s string) (v SemVer, n int, err error) {
+	var in = s
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, v.Prerelease, _ = strings.Cut(s, "-")
+	if s == "" {
+		return v, 0, fmt.Errorf("invalid version '%s'", in)
+	}
+	var parts = strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("invalid version '%s'", in)
+	}
+	var nums = []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		if *nums[i], err = strconv.Atoi(part); err != nil || *nums[i] < 0 {
+			return v, 0, fmt.Errorf("invalid version '%s'", in)
+		}
+	}
+	return v, len(parts), nil
+}
+
+// String implements fmt.Stringer.
+func (self SemVer) String() string {
+	var s = fmt.Sprintf("%d.%d.%d", self.Major, self.Minor, self.Patch)
+	if self.Prerelease != "" {
+		s += "-" + self.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1 if self precedes other, 1 if other precedes self and 0
+// if they are equal. A pre-release version precedes the release version.
+func (self SemVer) Compare(other SemVer) int {
+	if c := compareInt(self.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(self.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(self.Patch, other.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case self.Prerelease == other.Prerelease:
+		return 0
+	case self.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+	var a, b = strings.Split(self.Prerelease, "."), strings.Split(other.Prerelease, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		var an, aerr = strconv.Atoi(a[i])
+		var bn, berr = strconv.Atoi(b[i])
+		switch {
+		case aerr == nil && berr == nil:
+			if c := compareInt(an, bn); c != 0 {
+				return c
+			}
+		case aerr == nil:
+			// Numeric identifiers precede alphanumeric ones.
+			return -1
+		case berr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(a), len(b))
+}
+
+// compareInt compares a and b like SemVer.Compare.
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// VersionConstraint is a set of version comparisons that must all be true
+// for a version to satisfy the constraint.
+type VersionConstraint []versionComparison
+
+// versionComparison compares a version to version using op.
+type versionComparison struct {
+	op      string
+	version SemVer
+}
+
+// versionOperators lists comparison operators, longer ones first.
+var versionOperators = []string{">=", "<=", "!=", "==", ">", "<", "=", "^", "~"}
+
+// ParseVersionConstraint returns a VersionConstraint parsed from s or an
+// error. An empty s is satisfied by any version.
+//
+// A constraint is a list of comparisons separated by spaces or commas, i.e.
+// ">=1.2, <2". A comparison is an operator followed by a version, optionally
+// separated by spaces, i.e. ">= 1.2". Operators are "=" or "==", "!=", ">",
+// ">=", "<", "<=", "^" and "~". A version without an operator must be equal.
+//
+// "^1.2.3" allows changes that do not modify the leftmost non-zero number,
+// i.e. ">=1.2.3 <2.0.0", and "^0.2.3" is ">=0.2.3 <0.3.0". "~1.2.3" allows
+// patch changes, i.e. ">=1.2.3 <1.3.0", and "~1" is ">=1.0.0 <2.0.0".
+//
+// A pre-release version satisfies a constraint only if a comparison of the
+// constraint names a pre-release of the same major, minor and patch version,
+// so ">=1.2.3-beta" allows "1.2.3-rc1" but neither "1.3.0-rc1" nor
+// "2.0.0-rc1", as with npm.
+func ParseVersionConstraint(s string) (out VersionConstraint, err error) {
+	var fields = strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for i := 0; i < len(fields); i++ {
+		var field = fields[i]
+		if isVersionOperator(field) && i+1 < len(fields) {
+			i++
+			field += fields[i]
+		}
+		var op = "="
+		for _, o := range versionOperators {
+			if strings.HasPrefix(field, o) {
+				op, field = o, strings.TrimPrefix(field, o)
+				break
+			}
+		}
+		var (
+			v SemVer
+			n int
+		)
+		if v, n, err = parseSemVer(field); err != nil {
+			return nil, fmt.Errorf("invalid version constraint '%s': %w", s, err)
+		}
+		switch op {
+		case "==":
+			op = "="
+		case "^":
+			var upper SemVer
+			switch {
+			case v.Major > 0 || n == 1:
+				upper = SemVer{Major: v.Major + 1}
+			case v.Minor > 0 || n == 2:
+				upper = SemVer{Minor: v.Minor + 1}
+			default:
+				upper = SemVer{Minor: v.Minor, Patch: v.Patch + 1}
+			}
+			out = append(out, versionComparison{">=", v}, versionComparison{"<", upper})
+			continue
+		case "~":
+			var upper = SemVer{Major: v.Major, Minor: v.Minor + 1}
+			if n == 1 {
+				upper = SemVer{Major: v.Major + 1}
+			}
+			out = append(out, versionComparison{">=", v}, versionComparison{"<", upper})
+			continue
+		}
+		out = append(out, versionComparison{op, v})
+	}
+	return
+}
+
+// isVersionOperator returns true if s is one of versionOperators.
+func isVersionOperator(s string) bool {
+	for _, op := range versionOperators {
+		if s == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns true if v satisfies self.
+func (self VersionConstraint) Check(v SemVer) bool {
+	if v.Prerelease != "" && len(self) > 0 {
+		var allowed bool
+		for _, cmp := range self {
+			if cmp.version.Prerelease != "" && cmp.version.Major == v.Major &&
+				cmp.version.Minor == v.Minor && cmp.version.Patch == v.Patch {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	for _, cmp := range self {
+		var c = v.Compare(cmp.version)
+		var ok bool
+		switch cmp.op {
+		case "=":
+			ok = c == 0
+		case "!=":
+			ok = c != 0
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// String implements fmt.Stringer.
+func (self VersionConstraint) String() string {
+	var parts = make([]string, 0, len(self))
+	for _, cmp := range self {
+		parts = append(parts, cmp.op+cmp.version.String())
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseSemVer(t *testing.T) {
+	for _, test := range []struct {
+		in, out string
+		err     bool
+	}{
+		{"1.2.3", "1.2.3", false},
+		{"v1.2", "1.2.0", false},
+		{"1", "1.0.0", false},
+		{"1.2.3-rc.1+build", "1.2.3-rc.1", false},
+		{"", "", true},
+		{"1.2.3.4", "", true},
+		{"1.x", "", true},
+		{"-1.0", "", true},
+	} {
+		var v, err = ParseSemVer(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("%q: unexpected error %v", test.in, err)
+			continue
+		}
+		if err == nil && v.String() != test.out {
+			t.Errorf("%q: got %s, want %s", test.in, v, test.out)
+		}
+	}
+}
+
+func TestSemVerCompare(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		c    int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta", 1},
+	} {
+		var a, _ = ParseSemVer(test.a)
+		var b, _ = ParseSemVer(test.b)
+		if c := a.Compare(b); c != test.c {
+			t.Errorf("%s <=> %s: got %d, want %d", test.a, test.b, c, test.c)
+		}
+		if c := b.Compare(a); c != -test.c {
+			t.Errorf("%s <=> %s: got %d, want %d", test.b, test.a, c, -test.c)
+		}
+	}
+}
+
+func TestParseVersionConstraint(t *testing.T) {
+	for _, test := range []struct {
+		in, out string
+		err     bool
+	}{
+		{"", "", false},
+		{"1.2", "=1.2.0", false},
+		{"==1.2.3", "=1.2.3", false},
+		{">=1.2, <2", ">=1.2.0 <2.0.0", false},
+		{">= 1.2 < 2", ">=1.2.0 <2.0.0", false},
+		{"^ 1.2.3, != 1.5.0", ">=1.2.3 <2.0.0 !=1.5.0", false},
+		{"!=1.0.0-rc1", "!=1.0.0-rc1", false},
+		{"^1.2.3", ">=1.2.3 <2.0.0", false},
+		{"^0.2.3", ">=0.2.3 <0.3.0", false},
+		{"^0.0.3", ">=0.0.3 <0.0.4", false},
+		{"^0", ">=0.0.0 <1.0.0", false},
+		{"^0.0", ">=0.0.0 <0.1.0", false},
+		{"~1.2.3", ">=1.2.3 <1.3.0", false},
+		{"~1.2", ">=1.2.0 <1.3.0", false},
+		{"~1", ">=1.0.0 <2.0.0", false},
+		{">=", "", true},
+		{">= >=1", "", true},
+		{"^1.x", "", true},
+	} {
+		var c, err = ParseVersionConstraint(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("%q: unexpected error %v", test.in, err)
+			continue
+		}
+		if err == nil && c.String() != test.out {
+			t.Errorf("%q: got %q, want %q", test.in, c, test.out)
+		}
+	}
+}
+
+func TestVersionConstraintCheck(t *testing.T) {
+	for _, test := range []struct {
+		constraint, version string
+		ok                  bool
+	}{
+		{"", "1.0.0", true},
+		{"", "1.0.0-rc1", true},
+		{"1.2.3", "1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+		{"!=1.2.3", "1.2.4", true},
+		{">1.2.3", "1.2.3", false},
+		{">=1.2, <2", "1.9.9", true},
+		{">=1.2, <2", "2.0.0", false},
+		{"<=1.2.3", "1.2.3", true},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "1.2.2", false},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "2.0.0-rc1", false},
+		{"^1.2.3", "1.5.0-rc1", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1.2.3", "1.3.0-rc1", false},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0-rc1", false},
+		{"<2", "2.0.0-rc1", false},
+		{">=1.2.3-rc1", "1.2.3-rc2", true},
+		{">=1.2.3-rc1", "1.2.3", true},
+		{">=1.2.3-rc1", "1.2.4-rc1", false},
+		{">=1.2.3-rc2", "1.2.3-rc1", false},
+		{"^1.2.3-beta", "1.2.3-rc1", true},
+		{"^1.2.3-beta", "1.2.3-alpha", false},
+		{"^1.2.3-beta", "1.4.0", true},
+		{"^1.2.3-beta", "1.4.0-rc1", false},
+		{"1.0.0-rc1", "1.0.0-rc1", true},
+	} {
+		var c, err = ParseVersionConstraint(test.constraint)
+		if err != nil {
+			t.Fatalf("%q: %v", test.constraint, err)
+		}
+		var v SemVer
+		if v, err = ParseSemVer(test.version); err != nil {
+			t.Fatalf("%q: %v", test.version, err)
+		}
+		if ok := c.Check(v); ok != test.ok {
+			t.Errorf("%q check %s: got %t, want %t", test.constraint, test.version, ok, test.ok)
+		}
+	}
+}
+
+func TestRequiresExtendedVersion(t *testing.T) {
+
+	var repo = NewFSRepository(fstest.MapFS{
+		"base/boil.json": {Data: []byte(`{"version": "1.2.0"}`)},
+		"lib/boil.json":  {Data: []byte(`{"extends": "base"}`)},
+		"app/boil.json":  {Data: []byte(`{"requires": ["lib ^1.2"]}`)},
+		"old/boil.json":  {Data: []byte(`{"requires": ["lib ^2"]}`)},
+	})
+
+	if _, err := TasksFromMetafile(repo, "app", nil); err != nil {
+		t.Errorf("app: %v", err)
+	}
+	if _, err := TasksFromMetafile(repo, "old", nil); err == nil {
+		t.Error("old: expected unsatisfied requirement error")
+	}
+}
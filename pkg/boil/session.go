@@ -0,0 +1,103 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Session is a record of prompt answers given during a Template execution.
+//
+// An Executor records a Session if its Record field is set. A recorded
+// Session can be replayed using a ReplayPrompter to reproduce the execution
+// with the same answers.
+type Session struct {
+	// TemplatePath is the path of the executed Template.
+	TemplatePath string `json:"templatePath"`
+	// Created is the time the Session was recorded.
+	Created time.Time `json:"created"`
+	// Answers are the prompt answers in order as they were given.
+	Answers []*SessionAnswer `json:"answers"`
+}
+
+// SessionAnswer is a prompt answer in a Session.
+type SessionAnswer struct {
+	// Template is the path of the Template that defines the prompt.
+	Template string `json:"template"`
+	// Variable is the name of the prompt variable.
+	Variable string `json:"variable"`
+	// Value is the answer.
+	Value any `json:"value"`
+}
+
+// LoadSession loads a Session from a JSON file and returns it or an error.
+func LoadSession(filename string) (session *Session, err error) {
+	var data []byte
+	if data, err = os.ReadFile(filename); err != nil {
+		return nil, fmt.Errorf("read session file: %w", err)
+	}
+	session = new(Session)
+	if err = json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("unmarshal session file: %w", err)
+	}
+	return
+}
+
+// Save saves self to a JSON file or returns an error.
+func (self *Session) Save(filename string) (err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(self, "", "\t"); err != nil {
+		return fmt.Errorf("marshal session: %w", err)
+	}
+	if err = os.WriteFile(filename, data, 0666); err != nil {
+		return fmt.Errorf("write session file: %w", err)
+	}
+	return nil
+}
+
+// Add appends an answer to self.
+func (self *Session) Add(template, variable string, value any) {
+	self.Answers = append(self.Answers, &SessionAnswer{
+		Template: template,
+		Variable: variable,
+		Value:    value,
+	})
+}
+
+// NewReplayPrompter returns a new *ReplayPrompter that answers prompts from
+// session.
+func NewReplayPrompter(session *Session) *ReplayPrompter {
+	return &ReplayPrompter{
+		session: session,
+		used:    make([]bool, len(session.Answers)),
+	}
+}
+
+// ReplayPrompter is a Prompter that answers prompts with answers recorded in
+// a Session.
+//
+// Each prompt is answered by the first unused answer in the Session recorded
+// for the same Template and variable. If there is no such answer Prompt
+// returns an error.
+type ReplayPrompter struct {
+	session *Session
+	used    []bool
+}
+
+// Prompt implements Prompter.Prompt.
+func (self *ReplayPrompter) Prompt(templatePath string, prompt *Prompt, def string) (any, error) {
+	for i, answer := range self.session.Answers {
+		if self.used[i] || answer.Template != templatePath || answer.Variable != prompt.Variable {
+			continue
+		}
+		self.used[i] = true
+		return answer.Value, nil
+	}
+	return nil, fmt.Errorf("session has no answer for variable '%s' of template '%s'",
+		prompt.Variable, templatePath)
+}
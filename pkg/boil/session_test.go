@@ -0,0 +1,70 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExecutorReplaySession(t *testing.T) {
+
+	var (
+		args         []string
+		exec, output = newTestExecutor(typedRepository, typedVars, &args)
+		out          = filepath.Join(string(filepath.Separator), "out")
+		filename     = filepath.Join(t.TempDir(), "session.json")
+	)
+	exec.Record = new(Session)
+	if err := exec.Execute("svc", out); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := output.ReadFile(filepath.Join(out, "main.go")); err != nil || string(data) != typedOutput {
+		t.Fatalf("recorded: got %q, %v, want %q", data, err, typedOutput)
+	}
+	if exec.Record.TemplatePath != "svc" || len(exec.Record.Answers) != len(typedVars) {
+		t.Fatalf("unexpected session: %+v", exec.Record)
+	}
+	if err := exec.Record.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	// Numbers and lists decode from JSON as float64 and []any and are
+	// converted to prompt types on replay.
+	var session, err = LoadSession(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.TemplatePath != "svc" || !session.Created.Equal(exec.Record.Created) {
+		t.Errorf("loaded session: %+v", session)
+	}
+	var replay, replayOutput = newTestExecutor(typedRepository, nil, &args)
+	replay.Prompter = NewReplayPrompter(session)
+	if err = replay.Execute("svc", out); err != nil {
+		t.Fatal(err)
+	}
+	var data []byte
+	if data, err = replayOutput.ReadFile(filepath.Join(out, "main.go")); err != nil || string(data) != typedOutput {
+		t.Errorf("replayed: got %q, %v, want %q", data, err, typedOutput)
+	}
+	for name, want := range typedVars {
+		if got := replay.Data.Vars[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %#v, want %#v", name, got, want)
+		}
+	}
+
+	// Answers are used once, a replayed session without an answer for a
+	// presented prompt fails.
+	replay.Overwrite = true
+	if err = replay.Execute("svc", out); err == nil {
+		t.Error("expected error replaying a used session")
+	}
+	session.Answers = session.Answers[1:]
+	replay.Prompter = NewReplayPrompter(session)
+	if err = replay.Execute("svc", out); err == nil {
+		t.Error("expected error for missing answer")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitWords splits s into words. Words are separated by characters that
+// are not letters or digits and by changes of case, so "HTTPServer_v2",
+// "http-server v2" and "httpServerV2" all split into "HTTP" or "http",
+// "Server" or "server" and "v2" or "V2". Digits belong to the preceding word.
+// A lower case "s" that ends an upper case run belongs to it, so "URLsByID"
+// splits into "URLs", "By" and "ID".
+func splitWords(s string) (words []string) {
+	var (
+		runes = []rune(s)
+		start = -1
+	)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		var prev = runes[i-1]
+		if unicode.IsUpper(r) {
+			// "fooBar" splits before "B", "HTTPServer" splits before "S".
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) &&
+					!isPluralSuffix(runes, i+1)) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return
+}
+
+// isPluralSuffix returns true if runes[i] is an "s" that is the last rune
+// of runes or is followed by a rune that is not a lower case letter.
+func isPluralSuffix(runes []rune, i int) bool {
+	return runes[i] == 's' && (i+1 == len(runes) || !unicode.IsLower(runes[i+1]))
+}
+
+// snakeCase returns s as lower case words joined with "_", i.e. "foo_bar".
+func snakeCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "_"))
+}
+
+// screamingSnakeCase returns s as upper case words joined with "_",
+// i.e. "FOO_BAR".
+func screamingSnakeCase(s string) string {
+	return strings.ToUpper(strings.Join(splitWords(s), "_"))
+}
+
+// kebabCase returns s as lower case words joined with "-", i.e. "foo-bar".
+func kebabCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "-"))
+}
+
+// pascalCase returns s as capitalized words, i.e. "FooBar".
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		b.WriteString(capitalize(strings.ToLower(word)))
+	}
+	return b.String()
+}
+
+// camelCase returns s as capitalized words with the first word in lower
+// case, i.e. "fooBar".
+func camelCase(s string) string {
+	var b strings.Builder
+	for i, word := range splitWords(s) {
+		word = strings.ToLower(word)
+		if i > 0 {
+			word = capitalize(word)
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
+
+// titleCase returns s as capitalized words separated by spaces,
+// i.e. "Foo Bar".
+func titleCase(s string) string {
+	var words = splitWords(s)
+	for i, word := range words {
+		words[i] = capitalize(strings.ToLower(word))
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	for _, test := range []struct {
+		in  string
+		out []string
+	}{
+		{"", nil},
+		{"foo", []string{"foo"}},
+		{"fooBar", []string{"foo", "Bar"}},
+		{"HTTPServer_v2", []string{"HTTP", "Server", "v2"}},
+		{"http-server v2", []string{"http", "server", "v2"}},
+		{"httpServerV2", []string{"http", "Server", "V2"}},
+		{"ID", []string{"ID"}},
+		{"userID", []string{"user", "ID"}},
+		{"URLs", []string{"URLs"}},
+		{"URLsByID", []string{"URLs", "By", "ID"}},
+		{"parseURLs2", []string{"parse", "URLs2"}},
+		{"my_IDs", []string{"my", "IDs"}},
+		{"HTTPSession", []string{"HTTP", "Session"}},
+		{"__a--b__", []string{"a", "b"}},
+	} {
+		if words := splitWords(test.in); !reflect.DeepEqual(words, test.out) {
+			t.Errorf("%q: got %q, want %q", test.in, words, test.out)
+		}
+	}
+}
+
+func TestCase(t *testing.T) {
+	for _, test := range []struct {
+		in                                            string
+		camel, pascal, snake, kebab, screaming, title string
+	}{
+		{"", "", "", "", "", "", ""},
+		{"foo bar", "fooBar", "FooBar", "foo_bar", "foo-bar", "FOO_BAR", "Foo Bar"},
+		{"fooBar", "fooBar", "FooBar", "foo_bar", "foo-bar", "FOO_BAR", "Foo Bar"},
+		{"FOO_BAR", "fooBar", "FooBar", "foo_bar", "foo-bar", "FOO_BAR", "Foo Bar"},
+		{"HTTPServer", "httpServer", "HttpServer", "http_server", "http-server", "HTTP_SERVER", "Http Server"},
+		{"userID", "userId", "UserId", "user_id", "user-id", "USER_ID", "User Id"},
+		{"URLs", "urls", "Urls", "urls", "urls", "URLS", "Urls"},
+		{"parseURLs", "parseUrls", "ParseUrls", "parse_urls", "parse-urls", "PARSE_URLS", "Parse Urls"},
+		{"my-app v2", "myAppV2", "MyAppV2", "my_app_v2", "my-app-v2", "MY_APP_V2", "My App V2"},
+	} {
+		for _, c := range []struct {
+			name string
+			fn   func(string) string
+			want string
+		}{
+			{"camel", camelCase, test.camel},
+			{"pascal", pascalCase, test.pascal},
+			{"snake", snakeCase, test.snake},
+			{"kebab", kebabCase, test.kebab},
+			{"screaming", screamingSnakeCase, test.screaming},
+			{"title", titleCase, test.title},
+		} {
+			if out := c.fn(test.in); out != c.want {
+				t.Errorf("%s %q: got %q, want %q", c.name, test.in, out, c.want)
+			}
+		}
+	}
+}
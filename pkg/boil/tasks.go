@@ -0,0 +1,563 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Tasks is a list of Task.
+type Tasks []*Task
+
+// Task defines an execution task to perform for a template.
+type Task struct {
+	// Metafile is the Template Metafile.
+	Metafile *Metafile
+	// List is a list of actions to be performed for this template.
+	List []*Execute
+	// Vars are variable overrides of the Group members the Task was produced
+	// for. They override Data variables when the Task is executed.
+	// See GroupMember.Vars.
+	Vars Variables
+	// Partials are paths of partials directories whose files are parsed
+	// into the template set of each Task file, see PartialDirs.
+	Partials []string
+}
+
+// Data returns data with Vars of self overriding data variables or data if
+// self has no Vars.
+func (self *Task) Data(data *Data) *Data {
+	if len(self.Vars) == 0 {
+		return data
+	}
+	var out = *data
+	out.Vars = make(Variables, len(data.Vars)+len(self.Vars))
+	for k, v := range data.Vars {
+		out.Vars[k] = v
+	}
+	for k, v := range self.Vars {
+		out.Vars[k] = v
+	}
+	return &out
+}
+
+// Execute defines an execution action as part of a exec command task.
+type Execute struct {
+	// Path is the path to the template directory relative to repository root.
+	Path string
+	// Source is path of the template file or dir relative to repo root.
+	Source string
+	// Target is the absolute path of the target file which will contain Source
+	// template output. If the source path had placeholder values they will be
+	// replaced with actual values to generate output filenames.
+	Target string
+	// IsDir wil be true if Source is a directory.
+	IsDir bool
+	// When is the condition of the Metafile Entry this Execute was created
+	// from. See Entry.When and Tasks.FilterConditions.
+	When string
+	// Conflict is the ConflictPolicy for a Target that already exists.
+	// It is empty if Target does not exist. See Tasks.SetConflictPolicies.
+	Conflict ConflictPolicy
+}
+
+// TasksFromMetafile returns Tasks to be executed for a Template at path in
+// repo. Path may address a group in the Template using a "#" suffix in which
+// case the Tasks for group templates follow the Task of the Template.
+//
+// Templates required by a Template, see Metafile.Requires, precede the Task
+// of the Template, depth first. A Template is executed at most once for the
+// same variable overrides, regardless of how many Templates require it or
+// Groups include it, and required Template versions are checked against
+// constraints of each requirement.
+//
+// Optional Group members are selected using selector. If selector is nil
+// members selected by default are executed.
+//
+// It returns empty Tasks and an error if one or more template files is
+// missing, any group or requirement addresses a missing template, a required
+// template version does not satisfy a constraint, requirements or groups are
+// cyclic or some other error.
+func TasksFromMetafile(repo Repository, path string, selector MemberSelector) (tasks Tasks, err error) {
+	var producer = &taskProducer{
+		repo:     repo,
+		selector: selector,
+		produced: make(map[string]bool),
+	}
+	if err = producer.produce(path, nil); err != nil {
+		return nil, err
+	}
+	return producer.tasks, nil
+}
+
+// MemberSelector selects Optional members of a Group addressed by groupPath,
+// a Template path with a "#" and the Group name suffix, from optional and
+// returns the selected members or an error.
+type MemberSelector func(groupPath string, group *Group, optional GroupMembers) (selected GroupMembers, err error)
+
+// taskProducer produces Tasks from Metafiles, see TasksFromMetafile.
+type taskProducer struct {
+	// repo is the repository to load Templates from.
+	repo Repository
+	// selector selects optional group members, may be nil.
+	selector MemberSelector
+	// tasks are the produced Tasks.
+	tasks Tasks
+	// produced holds keys of Templates whose Tasks were produced.
+	produced map[string]bool
+	// requiring is a chain of Templates whose requirements are being
+	// produced, used to detect cycles.
+	requiring []string
+	// expanding is a chain of Groups being expanded, used to detect cycles.
+	expanding []string
+}
+
+// produce recursively constructs tasks starting from path. Tasks of the
+// Template at path get vars as overrides.
+// if the function failes it returns an error.
+func (self *taskProducer) produce(path string, vars Variables) (err error) {
+
+	var (
+		meta   *Metafile
+		group  string
+		exists bool
+		key    string
+	)
+
+	path, group, _ = strings.Cut(path, "#")
+	path = filepath.Clean(path)
+
+	for _, p := range self.requiring {
+		if p == path {
+			return fmt.Errorf("requires cycle: %s -> %s", strings.Join(self.requiring, " -> "), path)
+		}
+	}
+
+	if meta, err = self.repo.OpenMeta(path); err != nil {
+		return err
+	}
+	if meta, err = ResolveExtends(self.repo, meta); err != nil {
+		return err
+	}
+
+	key = path
+	if len(vars) > 0 {
+		var data []byte
+		if data, err = json.Marshal(vars); err != nil {
+			return fmt.Errorf("template %s: marshal vars: %w", path, err)
+		}
+		key += string(data)
+	}
+
+	if !self.produced[key] {
+
+		self.requiring = append(self.requiring, path)
+		for _, s := range meta.Requires {
+			var (
+				req      Requirement
+				required *Metafile
+			)
+			if req, err = ParseRequirement(s); err != nil {
+				return fmt.Errorf("template %s: %w", path, err)
+			}
+			if required, err = self.repo.OpenMeta(req.Path); err != nil {
+				return fmt.Errorf("template %s: open required template %s: %w", path, req.Path, err)
+			}
+			if required, err = ResolveExtends(self.repo, required); err != nil {
+				return fmt.Errorf("template %s: resolve required template %s: %w", path, req.Path, err)
+			}
+			if err = req.Check(required); err != nil {
+				return fmt.Errorf("template %s: %w", path, err)
+			}
+			if err = self.produce(req.Path, nil); err != nil {
+				return
+			}
+		}
+		self.requiring = self.requiring[:len(self.requiring)-1]
+
+		var template = &Task{
+			Metafile: meta,
+			Vars:     vars,
+		}
+		if template.Partials, err = PartialDirs(self.repo, meta); err != nil {
+			return fmt.Errorf("template %s: %w", path, err)
+		}
+
+		for _, dir := range meta.Directories {
+			template.List = append(template.List, &Execute{
+				Path:   dir.Path,
+				Source: dir.Source(path),
+				IsDir:  true,
+				When:   dir.When,
+			})
+		}
+
+		for _, file := range meta.Files {
+			if exists, err = self.repo.Exists(file.Source(path)); err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("template file '%s' does not exist", file.Source(path))
+			}
+			template.List = append(template.List, &Execute{
+				Path:   file.Path,
+				Source: file.Source(path),
+				IsDir:  false,
+				When:   file.When,
+			})
+		}
+
+		self.tasks = append(self.tasks, template)
+		self.produced[key] = true
+	}
+
+	if group != "" {
+		return self.expand(meta, path, group, vars)
+	}
+
+	return nil
+}
+
+// expand produces tasks for members of the Group named name defined by meta
+// of the Template at path, including members of nested Groups. Members get
+// vars overridden by their own Vars.
+func (self *taskProducer) expand(meta *Metafile, path, name string, vars Variables) (err error) {
+
+	var (
+		groupPath = path + "#" + name
+		group     *Group
+	)
+
+	for _, g := range meta.Groups {
+		if g.Name == name {
+			group = g
+			break
+		}
+	}
+	if group == nil {
+		return fmt.Errorf("template %s does not define group '%s'", path, name)
+	}
+
+	for _, p := range self.expanding {
+		if p == groupPath {
+			return fmt.Errorf("group cycle: %s -> %s", strings.Join(self.expanding, " -> "), groupPath)
+		}
+	}
+	self.expanding = append(self.expanding, groupPath)
+	defer func() { self.expanding = self.expanding[:len(self.expanding)-1] }()
+
+	for _, nested := range group.Groups {
+		if err = self.expand(meta, path, nested, vars); err != nil {
+			return
+		}
+	}
+
+	var optional, selected GroupMembers
+	for _, member := range group.Templates {
+		if !member.Optional {
+			continue
+		}
+		optional = append(optional, member)
+		if member.Selected {
+			selected = append(selected, member)
+		}
+	}
+	if len(optional) > 0 && self.selector != nil {
+		if selected, err = self.selector(groupPath, group, optional); err != nil {
+			return fmt.Errorf("select members of group %s: %w", groupPath, err)
+		}
+	}
+
+	for _, member := range group.Templates {
+		if member.Optional && !containsMember(selected, member) {
+			continue
+		}
+		var memberVars = vars
+		if len(member.Vars) > 0 {
+			memberVars = make(Variables, len(vars)+len(member.Vars))
+			for k, v := range vars {
+				memberVars[k] = v
+			}
+			for k, v := range member.Vars {
+				memberVars[k] = v
+			}
+		}
+		if err = self.produce(filepath.Join(group.Dir(path), member.Template), memberVars); err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+// containsMember returns true if members contains member.
+func containsMember(members GroupMembers, member *GroupMember) bool {
+	for _, m := range members {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
+
+// TasksFromWalk returns Tasks to be executed from walking the repo starting
+// at the root directory or an error if one occured. It returns a single Task
+// without a Metafile that holds all Executes. Partials directories are not
+// walked, partials in the repo root, the root directory and directories
+// between them are available to the Task files, see PartialsDir.
+func TasksFromWalk(repo Repository, root string) (out Tasks, err error) {
+	var task = new(Task)
+	if task.Partials, err = existingPartialDirs(repo, ancestorDirs(root)...); err != nil {
+		return nil, err
+	}
+	if err = repo.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if root == path {
+			return nil
+		}
+		if d.IsDir() && IsPartialsDir(path) {
+			return fs.SkipDir
+		}
+		var (
+			exe = new(Execute)
+			rel string
+		)
+		if rel, err = filepath.Rel(root, path); err != nil {
+			return err
+		}
+		exe.Path = rel
+		exe.Source = path
+		exe.IsDir = d.IsDir()
+		task.List = append(task.List, exe)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("enumerate source files: %w", err)
+	}
+	out = append(out, task)
+	return
+}
+
+// FilterConditions evaluates When conditions of all Executes in self using
+// data and removes Executes whose conditions are false. It returns an error
+// if a condition fails to evaluate.
+func (self Tasks) FilterConditions(data *Data) (err error) {
+	for _, task := range self {
+		var list = task.List[:0]
+		for _, exec := range task.List {
+			if exec.When != "" {
+				var include bool
+				if include, err = EvaluateCondition(exec.When, task.Data(data)); err != nil {
+					return fmt.Errorf("entry '%s': %w", exec.Path, err)
+				}
+				if !include {
+					continue
+				}
+			}
+			list = append(list, exec)
+		}
+		task.List = list
+	}
+	return nil
+}
+
+// SetTargets expands placeholders in each execution.Path of self using
+// variables of data, see Variables.ExpandPath, and sets each
+// execution.Target to the absolute path of the result in the outputDir.
+// Returns an error if one occurs, including if an expanded path is outside
+// of outputDir, or nil.
+func (self Tasks) SetTargets(outputDir string, data *Data) (err error) {
+	for _, tmpl := range self {
+		var vars = tmpl.Data(data).Vars
+		for _, execution := range tmpl.List {
+			var target string
+			if target, err = vars.ExpandPath(execution.Path); err != nil {
+				return fmt.Errorf("execution %s: %w", execution.Source, err)
+			}
+			execution.Target = filepath.Join(
+				outputDir,
+				filepath.FromSlash(target),
+			)
+			if !isSubPath(outputDir, execution.Target) {
+				return fmt.Errorf("execution %s: path '%s' expands to '%s' outside of the output directory",
+					execution.Source, execution.Path, target)
+			}
+		}
+	}
+	return
+}
+
+// SetConflictPolicies sets the Conflict policy of each Execute in self whose
+// Target already exists in output.
+//
+// The policy is taken from the first matching rule in the Task Metafile
+// Conflicts, then from def. If def is empty ConflictFail is used.
+// An error is returned for the first existing Target whose policy is
+// ConflictFail, except for directories which are created if missing
+// regardless of policy.
+func (self Tasks) SetConflictPolicies(output OutputFS, outputDir string, def ConflictPolicy) (err error) {
+	if def == "" {
+		def = ConflictFail
+	}
+	for _, task := range self {
+		for _, exec := range task.List {
+			exec.Conflict = ""
+			if _, err = output.Stat(exec.Target); err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					return fmt.Errorf("stat target file: %w", err)
+				}
+				err = nil
+				continue
+			}
+			var policy = def
+			if task.Metafile != nil {
+				var rel string
+				if rel, err = filepath.Rel(outputDir, exec.Target); err != nil {
+					return fmt.Errorf("target path: %w", err)
+				}
+				if p := task.Metafile.Conflicts.PolicyFor(filepath.ToSlash(rel)); p != "" {
+					if policy, err = ParseConflictPolicy(string(p)); err != nil {
+						return fmt.Errorf("template '%s': %w", task.Metafile.Path, err)
+					}
+				}
+			}
+			if policy == ConflictFail {
+				if exec.IsDir {
+					continue
+				}
+				return fmt.Errorf("target file already exists: %s", exec.Target)
+			}
+			exec.Conflict = policy
+		}
+	}
+	return nil
+}
+
+// Validate validates metafiles of each Task in self for execution. It returns
+// the first validation error that occurs or nil if all passed.
+//
+// Unlike Metafile.Validate it does not check that entries exist or that
+// their placeholders refer to declared variables as these are checked on
+// execution against actual variables.
+func (self Tasks) Validate(repo Repository) (err error) {
+	for _, template := range self {
+		if template.Metafile == nil {
+			continue
+		}
+		if err = template.Metafile.validate(repo, false); err != nil {
+			break
+		}
+	}
+	return
+}
+
+// ExecPreParseActions executes all PreParse actions defined in all metafiles
+// in the order they are defined, depth first, using runner. The first error
+// that occurs from any action is returned and execution stopped or nil if
+// everything successed.
+func (self Tasks) ExecPreParseActions(runner ActionRunner) (err error) {
+	for _, template := range self {
+		if template.Metafile == nil {
+			continue
+		}
+		if err = template.Metafile.Actions.PreParse.RunAll(runner, nil); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ExecPreExecuteActions executes all PreExecute actions defined in all
+// metafiles in the order they are defined, depth first, using runner. The
+// first error that occurs from any action is returned and execution stopped
+// or nil if everything successed.
+func (self Tasks) ExecPreExecuteActions(runner ActionRunner, data *Data) (err error) {
+	for _, template := range self {
+		if template.Metafile == nil {
+			continue
+		}
+		if err = template.Metafile.Actions.PreExecute.RunAll(runner, template.Data(data)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ExecPostExecuteActions executes all PostExecute actions defined in all
+// metafiles in the order they are defined, depth first, using runner. The
+// first error that occurs from any action is returned and execution stopped
+// or nil if everything successed.
+func (self Tasks) ExecPostExecuteActions(runner ActionRunner, data *Data) (err error) {
+	for _, template := range self {
+		if template.Metafile == nil {
+			continue
+		}
+		if err = template.Metafile.Actions.PostExecute.RunAll(runner, template.Data(data)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Template returns the first Task in self of the Template at path, which may
+// have a "#" group suffix, or nil if not found. Tasks of required Templates
+// and Group members precede or follow it, see TasksFromMetafile.
+func (self Tasks) Template(path string) *Task {
+	path, _, _ = strings.Cut(path, "#")
+	path = filepath.Clean(path)
+	for _, task := range self {
+		if task.Metafile != nil && filepath.Clean(task.Metafile.Path) == path {
+			return task
+		}
+	}
+	return nil
+}
+
+// Targets returns Target paths of all file and directory executions of all
+// tasks in self. For files whose Conflict policy may write a side file the
+// side file path is included as well.
+func (self Tasks) Targets() (files, dirs []string) {
+	for _, task := range self {
+		for _, item := range task.List {
+			if item.IsDir {
+				dirs = append(dirs, item.Target)
+				continue
+			}
+			files = append(files, item.Target)
+			if item.Conflict == ConflictNew || item.Conflict == ConflictPrompt {
+				files = append(files, item.Target+SideFileExt)
+			}
+		}
+	}
+	return
+}
+
+// Print prints self to wr.
+func (self Tasks) Print(wr io.Writer) {
+	if len(self) == 0 {
+		return
+	}
+	fmt.Fprintf(wr, "Tasks:\n")
+	for _, task := range self {
+		if task.Metafile != nil {
+			fmt.Fprintf(wr, "[Template]\t[Source]\t[Target]\n")
+			for _, def := range task.List {
+				fmt.Fprintf(wr, "%s\t%s\t%s\n", task.Metafile.Path, def.Source, def.Target)
+			}
+			continue
+		}
+		fmt.Fprintf(wr, "[Source]\t[Target]\n")
+		for _, def := range task.List {
+			fmt.Fprintf(wr, "%s\t%s\n", def.Source, def.Target)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testGroupRepository is a repository with a Template that defines nested
+// Groups with optional members and member variables.
+var testGroupRepository = fstest.MapFS{
+	"app/boil.json": {Data: []byte(`{
+		"files": [{"path": "main.go"}],
+		"groups": [
+			{"name": "minimal", "templates": ["cli"]},
+			{"name": "full", "groups": ["minimal"], "templates": [
+				{"template": "docs", "optional": true, "selected": true},
+				{"template": "ci", "optional": true},
+				{"template": "db", "vars": {"Driver": "postgres"}}
+			]},
+			{"name": "all", "groups": ["full", "minimal"], "templates": ["cli", "other"]},
+			{"name": "drivers", "templates": [
+				{"template": "db", "vars": {"Driver": "mysql"}},
+				{"template": "db", "vars": {"Driver": "sqlite"}}
+			]},
+			{"name": "loop", "groups": ["cycle"]},
+			{"name": "cycle", "groups": ["loop"]}
+		]
+	}`)},
+	"app/main.go":         {},
+	"app/cli/boil.json":   {Data: []byte(`{"files": [{"path": "cli.go"}]}`)},
+	"app/cli/cli.go":      {},
+	"app/docs/boil.json":  {Data: []byte(`{"files": [{"path": "docs.md"}]}`)},
+	"app/docs/docs.md":    {},
+	"app/ci/boil.json":    {Data: []byte(`{"files": [{"path": "ci.yml"}]}`)},
+	"app/ci/ci.yml":       {},
+	"app/db/boil.json":    {Data: []byte(`{"files": [{"path": "db.go"}]}`)},
+	"app/db/db.go":        {},
+	"app/other/boil.json": {Data: []byte(`{"files": [{"path": "other.go"}]}`)},
+	"app/other/other.go":  {},
+}
+
+// taskPaths returns slash separated Template paths of tasks.
+func taskPaths(tasks Tasks) (paths []string) {
+	for _, task := range tasks {
+		paths = append(paths, filepath.ToSlash(task.Metafile.Path))
+	}
+	return
+}
+
+func TestTasksFromMetafileGroups(t *testing.T) {
+
+	var repo = NewFSRepository(testGroupRepository)
+
+	// selectNone selects no optional members, selectCI selects "ci" only.
+	var (
+		selectNone = func(groupPath string, group *Group, optional GroupMembers) (GroupMembers, error) {
+			return nil, nil
+		}
+		selectCI = func(groupPath string, group *Group, optional GroupMembers) (GroupMembers, error) {
+			if groupPath != "app#full" || len(optional) != 2 {
+				return nil, errors.New("unexpected optional members")
+			}
+			return GroupMembers{optional[1]}, nil
+		}
+	)
+
+	for _, test := range []struct {
+		path     string
+		selector MemberSelector
+		paths    []string
+		err      string
+	}{
+		{path: "app", paths: []string{"app"}},
+		{path: "app#minimal", paths: []string{"app", "app/cli"}},
+		{path: "app#full", paths: []string{"app", "app/cli", "app/docs", "app/db"}},
+		{path: "app#full", selector: selectNone, paths: []string{"app", "app/cli", "app/db"}},
+		{path: "app#full", selector: selectCI, paths: []string{"app", "app/cli", "app/ci", "app/db"}},
+		{path: "app#all", paths: []string{"app", "app/cli", "app/docs", "app/db", "app/other"}},
+		{path: "app#drivers", paths: []string{"app", "app/db", "app/db"}},
+		{path: "app#missing", err: "does not define group 'missing'"},
+		{path: "app#loop", err: "group cycle: app#loop -> app#cycle -> app#loop"},
+	} {
+		var tasks, err = TasksFromMetafile(repo, test.path, test.selector)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: expected error %q, got %v", test.path, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		if paths := taskPaths(tasks); !reflect.DeepEqual(paths, test.paths) {
+			t.Errorf("%s: got %v, want %v", test.path, paths, test.paths)
+		}
+	}
+}
+
+func TestTasksFromMetafileMemberVars(t *testing.T) {
+
+	var repo = NewFSRepository(testGroupRepository)
+
+	var tasks, err = TasksFromMetafile(repo, "app#drivers", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data = &Data{Vars: Variables{"Driver": "none", "Name": "app"}}
+	for i, want := range []string{"none", "mysql", "sqlite"} {
+		var vars = tasks[i].Data(data).Vars
+		if vars["Driver"] != want || vars["Name"] != "app" {
+			t.Errorf("task %d: got vars %v, want Driver %s", i, vars, want)
+		}
+	}
+	if data.Vars["Driver"] != "none" {
+		t.Error("member vars modified shared data")
+	}
+
+	// Member vars override Data when executed.
+	var (
+		args         []string
+		exec, output = newTestExecutor(fstest.MapFS{
+			"app/boil.json": {Data: []byte(`{
+				"prompts": [{"variable": "Driver", "default": "none"}],
+				"groups": [{"name": "all", "templates": [{"template": "db", "vars": {"Driver": "postgres"}}]}]
+			}`)},
+			"app/db/boil.json": {Data: []byte(`{"files": [{"path": "db.go"}]}`)},
+			"app/db/db.go":     {Data: []byte(`{{.Vars.Driver}}`)},
+		}, nil, &args)
+		out = filepath.Join(string(filepath.Separator), "out")
+	)
+	if err = exec.Execute("app#all", out); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := output.ReadFile(filepath.Join(out, "db.go")); err != nil || string(data) != "postgres" {
+		t.Errorf("member output: got %q, %v", data, err)
+	}
+}
@@ -2,7 +2,11 @@ package boil
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 	"text/template"
+
+	"github.com/vedranvuk/bast/pkg/bast"
 )
 
 // FuncMapper can return a template.FuncMap.
@@ -10,7 +14,7 @@ type FuncMapper interface {
 	FuncMap() template.FuncMap
 }
 
-// ExecuteTemplateString executes in as a text/template using data and returns it or 
+// ExecuteTemplateString executes in as a text/template using data and returns it or
 // an error. If data supports FuncMapper the functions are added to the template.
 func ExecuteTemplateString(in string, data any) (out string, err error) {
 	var (
@@ -27,4 +31,37 @@ func ExecuteTemplateString(in string, data any) (out string, err error) {
 		return
 	}
 	return buff.String(), nil
-}
\ No newline at end of file
+}
+
+// EvaluateCondition evaluates expr as a text/template pipeline using data and
+// returns true if the pipeline value is not empty, as defined by the "if"
+// template action. Optional enclosing delimiters are removed from expr so both
+// ".Vars.UseDocker" and "{{.Vars.UseDocker}}" are accepted.
+func EvaluateCondition(expr string, data any) (result bool, err error) {
+	expr = conditionPipeline(expr)
+	var out string
+	if out, err = ExecuteTemplateString("{{if "+expr+"}}true{{end}}", data); err != nil {
+		return false, fmt.Errorf("evaluate condition '%s': %w", expr, err)
+	}
+	return out == "true", nil
+}
+
+// ParseCondition returns an error if expr is not a valid condition pipeline.
+// See EvaluateCondition.
+func ParseCondition(expr string) (err error) {
+	expr = conditionPipeline(expr)
+	var funcs = (&Data{Bast: bast.New()}).FuncMap()
+	if _, err = template.New("condition").Funcs(funcs).Parse("{{if " + expr + "}}true{{end}}"); err != nil {
+		return fmt.Errorf("parse condition '%s': %w", expr, err)
+	}
+	return nil
+}
+
+// conditionPipeline returns expr with optional enclosing delimiters removed.
+func conditionPipeline(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{{") && strings.HasSuffix(expr, "}}") {
+		expr = strings.TrimSpace(expr[2 : len(expr)-2])
+	}
+	return expr
+}
@@ -0,0 +1,344 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ValidationError is a problem found in a Metafile by Metafile.Validate.
+type ValidationError struct {
+	// File is the path of the Metafile relative to the repository root.
+	File string `json:"file"`
+	// Field is the location of the invalid value in the Metafile in a
+	// "prompts[1].regexp" format. It is empty if the problem concerns the
+	// Metafile as a whole.
+	Field string `json:"field,omitempty"`
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// Error implements error.
+func (self *ValidationError) Error() string {
+	if self.Field == "" {
+		return fmt.Sprintf("%s: %s", self.File, self.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", self.File, self.Field, self.Message)
+}
+
+// ValidationErrors is a list of problems found in one or more Metafiles.
+type ValidationErrors []*ValidationError
+
+// Error implements error. It returns all errors in self, one per line.
+func (self ValidationErrors) Error() string {
+	var lines = make([]string, 0, len(self))
+	for _, err := range self {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// add appends a new ValidationError to self.
+func (self *ValidationErrors) add(file, field, format string, args ...any) {
+	*self = append(*self, &ValidationError{
+		File:    file,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Validate validates self against the Template files in repo.
+//
+// It checks that Files and Directories entries exist in the Template
+// directory, that placeholders in their paths refer to declared prompts or
+// standard variables and use known filters and that their paths do not use
+// template actions, which are not expanded in paths. It checks that prompts
+// are well formed and define unique variables, that conflict rules are
+// valid, that Group Templates address child Templates, that nested Groups
+// are defined and that required Templates exist and satisfy version
+// constraints.
+//
+// If self extends a Template, the extended Template must exist and self is
+// validated merged with it, see ResolveExtends. Inherited entries are
+// checked against the directory of the Template that defines them.
+//
+// If any problems are found they are returned as ValidationErrors, otherwise
+// Validate returns nil. Errors accessing the repository are returned as
+// they occur.
+func (self Metafile) Validate(repo Repository) (err error) {
+	return self.validate(repo, true)
+}
+
+// validate implements Validate. If strict is false checks that depend on
+// the execution are skipped: existence of Files and Directories entries and
+// declaration of variables used in their placeholders, as variables may be
+// given on command line and missing directories are created on execution.
+func (self Metafile) validate(repo Repository, strict bool) (err error) {
+
+	var (
+		errs  ValidationErrors
+		file  = self.File()
+		known = make(map[string]bool)
+		seen  = make(map[string]int)
+	)
+
+	if self.Extends != "" {
+		var (
+			resolved *Metafile
+			exists   bool
+		)
+		if exists, err = repo.HasMeta(self.Extends); err != nil {
+			return fmt.Errorf("check extended template %s: %w", self.Extends, err)
+		}
+		if !exists {
+			errs.add(file, "extends", "extended template '%s' does not exist", self.Extends)
+		} else if resolved, err = ResolveExtends(repo, &self); err != nil {
+			errs.add(file, "extends", "%v", err)
+		} else {
+			self = *resolved
+		}
+	}
+
+	for _, name := range StdVariables {
+		known[name] = true
+	}
+
+	// Variables prompted for by required Templates are known as well.
+	var requires = make(map[string]int)
+	for i, s := range self.Requires {
+		var (
+			field    = fmt.Sprintf("requires[%d]", i)
+			req      Requirement
+			required *Metafile
+			exists   bool
+		)
+		if req, err = ParseRequirement(s); err != nil {
+			errs.add(file, field, "%v", err)
+			err = nil
+			continue
+		}
+		if j, exists := requires[req.Path]; exists {
+			errs.add(file, field, "template '%s' already required by requires[%d]", req.Path, j)
+			continue
+		}
+		requires[req.Path] = i
+		if req.Path == filepath.Clean(self.Path) {
+			errs.add(file, field, "template requires itself")
+			continue
+		}
+		if exists, err = repo.HasMeta(req.Path); err != nil {
+			return fmt.Errorf("check required template %s: %w", req.Path, err)
+		}
+		if !exists {
+			errs.add(file, field, "required template '%s' does not exist", req.Path)
+			continue
+		}
+		if required, err = repo.OpenMeta(req.Path); err != nil {
+			return fmt.Errorf("open required template %s: %w", req.Path, err)
+		}
+		if resolved, err := ResolveExtends(repo, required); err == nil {
+			required = resolved
+		}
+		if err = req.Check(required); err != nil {
+			errs.add(file, field, "%v", err)
+			err = nil
+		}
+		for _, prompt := range required.Prompts {
+			known[prompt.Variable] = true
+		}
+	}
+
+	for i, prompt := range self.Prompts {
+		var field = fmt.Sprintf("prompts[%d]", i)
+		if prompt.Variable == "" {
+			errs.add(file, field+".variable", "variable is empty")
+		} else if j, exists := seen[prompt.Variable]; exists {
+			errs.add(file, field+".variable", "variable '%s' already declared by prompts[%d]", prompt.Variable, j)
+		} else {
+			seen[prompt.Variable] = i
+			known[prompt.Variable] = true
+		}
+		if !isPromptType(prompt.GetType()) {
+			errs.add(file, field+".type", "invalid prompt type '%s'", prompt.Type)
+			continue
+		}
+		if prompt.RegExp != "" {
+			if _, err := regexp.Compile(prompt.RegExp); err != nil {
+				errs.add(file, field+".regexp", "invalid regular expression: %v", err)
+			}
+		}
+		switch prompt.GetType() {
+		case PromptChoice, PromptMultiChoice:
+			if len(prompt.Choices) == 0 {
+				errs.add(file, field+".choices", "%s prompt defines no choices", prompt.GetType())
+			}
+		default:
+			if len(prompt.Choices) > 0 {
+				errs.add(file, field+".choices", "choices are not valid for a %s prompt", prompt.GetType())
+			}
+		}
+		// Templated defaults depend on execution data and are checked when
+		// they are executed.
+		if prompt.Default != "" && !strings.Contains(prompt.Default, "{{") {
+			if value, err := prompt.ParseValue(prompt.Default); err != nil {
+				errs.add(file, field+".default", "%v", err)
+			} else if err = prompt.CheckValue(value); err != nil {
+				errs.add(file, field+".default", "%v", err)
+			}
+		}
+		if prompt.When != "" {
+			if err := ParseCondition(prompt.When); err != nil {
+				errs.add(file, field+".when", "%v", err)
+			}
+		}
+	}
+
+	if err = self.validateEntries(repo, &errs, file, "directories", self.Directories, known, strict); err != nil {
+		return
+	}
+	if err = self.validateEntries(repo, &errs, file, "files", self.Files, known, strict); err != nil {
+		return
+	}
+
+	for i, rule := range self.Conflicts {
+		var field = fmt.Sprintf("conflicts[%d]", i)
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			errs.add(file, field+".pattern", "invalid pattern '%s': %v", rule.Pattern, err)
+		}
+		if _, err := ParseConflictPolicy(string(rule.Policy)); err != nil {
+			errs.add(file, field+".policy", "%v", err)
+		}
+	}
+
+	var groups = make(map[string]int)
+	for i, group := range self.Groups {
+		var field = fmt.Sprintf("groups[%d]", i)
+		if group.Name == "" {
+			errs.add(file, field+".name", "group name is empty")
+		} else if j, exists := groups[group.Name]; exists {
+			errs.add(file, field+".name", "group '%s' already defined by groups[%d]", group.Name, j)
+		} else {
+			groups[group.Name] = i
+		}
+		for j, member := range group.Templates {
+			var (
+				item   = fmt.Sprintf("%s.templates[%d]", field, j)
+				exists bool
+			)
+			// A group template may address a group of the child template.
+			var child, _, _ = strings.Cut(member.Template, "#")
+			if !isChildPath(child) {
+				errs.add(file, item, "'%s' is not a path to a child template", member.Template)
+				continue
+			}
+			if exists, err = repo.HasMeta(filepath.Join(group.Dir(self.Path), child)); err != nil {
+				return fmt.Errorf("check group template %s: %w", member.Template, err)
+			}
+			if !exists {
+				errs.add(file, item, "child template '%s' does not exist", child)
+			}
+			if member.Selected && !member.Optional {
+				errs.add(file, item+".selected", "only optional members can be selected")
+			}
+			for name := range member.Vars {
+				if name == "" {
+					errs.add(file, item+".vars", "variable name is empty")
+				}
+			}
+		}
+	}
+
+	// Nested groups are checked once all group names are known.
+	for i, group := range self.Groups {
+		for j, name := range group.Groups {
+			var item = fmt.Sprintf("groups[%d].groups[%d]", i, j)
+			if name == group.Name {
+				errs.add(file, item, "group '%s' includes itself", name)
+			} else if _, exists := groups[name]; !exists {
+				errs.add(file, item, "group '%s' is not defined", name)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateEntries validates entries defined under the field name in self and
+// appends problems to errs. If strict is true, entries must exist and
+// placeholders in entry paths must be keys in known.
+func (self Metafile) validateEntries(repo Repository, errs *ValidationErrors, file, name string, entries Entries, known map[string]bool, strict bool) (err error) {
+	for i, entry := range entries {
+		var (
+			field  = fmt.Sprintf("%s[%d]", name, i)
+			exists = true
+		)
+		if !isChildPath(entry.Path) {
+			errs.add(file, field, "'%s' is not a path inside the template directory", entry.Path)
+			continue
+		}
+		if strict {
+			if exists, err = repo.Exists(entry.Source(self.Path)); err != nil {
+				return fmt.Errorf("check %s: %w", entry.Path, err)
+			}
+		}
+		if !exists {
+			if entry.Template != "" {
+				errs.add(file, field, "'%s' does not exist in the extended template '%s'", entry.Path, entry.Template)
+			} else {
+				errs.add(file, field, "'%s' does not exist in the template directory", entry.Path)
+			}
+		}
+		if strings.Contains(entry.Path, "{{") {
+			errs.add(file, field, "'%s' contains a template action, use placeholders such as '$Name|snake' in paths", entry.Path)
+		}
+		if _, err := expandPlaceholders(entry.Path,
+			func(name string) bool { return known[name] },
+			func(name string, filters []string) (string, error) {
+				if strict && !known[name] {
+					errs.add(file, field, "placeholder '$%s' does not refer to a prompt or a standard variable", name)
+				}
+				if _, err := applyPathFilters("", filters); err != nil {
+					errs.add(file, field, "placeholder '$%s': %v", name, err)
+				}
+				return "", nil
+			},
+		); err != nil {
+			errs.add(file, field, "%v", err)
+		}
+		if entry.When != "" {
+			if err := ParseCondition(entry.When); err != nil {
+				errs.add(file, field+".when", "%v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// isPromptType returns true if t is one of PromptTypes.
+func isPromptType(t PromptType) bool {
+	for _, v := range PromptTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// isChildPath returns true if p is a non empty relative path that does not
+// escape the directory it is relative to.
+func isChildPath(p string) bool {
+	if p == "" || filepath.IsAbs(p) || strings.HasPrefix(p, "/") {
+		return false
+	}
+	p = filepath.Clean(p)
+	return p != "." && p != ".." && !strings.HasPrefix(p, ".."+string(filepath.Separator))
+}
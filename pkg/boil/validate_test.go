@@ -0,0 +1,75 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package boil
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestValidate(t *testing.T) {
+
+	var repo = NewFSRepository(fstest.MapFS{
+		"parent/boil.json": {Data: []byte(`{
+			"files": [{"path": "a.go"}, {"path": "missing.go"}]
+		}`)},
+		"parent/a.go": {},
+		"child/boil.json": {Data: []byte(`{
+			"extends": "parent",
+			"remove": {"files": ["missing.go"]},
+			"files": [{"path": "b.go"}]
+		}`)},
+		"child/b.go": {},
+		"app/boil.json": {Data: []byte(`{
+			"directories": [{"path": "missing"}],
+			"files": [{"path": "$Given.go"}],
+			"prompts": [{"variable": "Port", "type": "int", "default": "port"}]
+		}`)},
+		"app/$Given.go": {},
+		"old/boil.json": {Data: []byte(`{
+			"files": [{"path": "{{.Vars.Name}}.go"}],
+			"prompts": [{"variable": "Name"}]
+		}`)},
+		"old/{{.Vars.Name}}.go": {},
+	})
+
+	var validate = func(path string, resolve bool) error {
+		t.Helper()
+		var meta, err = repo.OpenMeta(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resolve {
+			if meta, err = ResolveExtends(repo, meta); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return meta.Validate(repo)
+	}
+
+	if err := validate("child", false); err != nil {
+		t.Errorf("child: %v", err)
+	}
+	if err := validate("child", true); err != nil {
+		t.Errorf("resolved child: %v", err)
+	}
+
+	// Strict checks fail, execution checks fail only on the prompt default.
+	var errs ValidationErrors
+	if err := validate("app", false); !errors.As(err, &errs) || len(errs) != 3 {
+		t.Errorf("app: expected 3 validation errors, got %v", err)
+	}
+	if err := validate("old", false); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "files[0]" {
+		t.Errorf("old: expected a template action path error, got %v", err)
+	}
+	var tasks, err = TasksFromMetafile(repo, "app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tasks.Validate(repo); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "prompts[0].default" {
+		t.Errorf("app tasks: expected a prompt default error, got %v", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Variable defines a known variable.
@@ -69,16 +70,37 @@ func (self Variable) String() string { return StdVariables[self] }
 // command or defined by the user on Template execution via command line.
 type Variables map[string]any
 
-// ReplacePlaceholders replaces all known variable placeholders in input string
-// with actual values and returns it.
+// ExpandPath returns the path in with variable placeholders replaced by
+// values of variables in self or an error. It is the syntax of Template file
+// and directory paths.
 //
-// A placeholder is a case sensitive variable name prefixed with "$".
-func (self Variables) ReplacePlaceholders(in string) (out string) {
-	out = in
-	for k, v := range self {
-		out = strings.ReplaceAll(out, "$"+k, v.(string))
-	}
-	return out
+// A placeholder is a case sensitive variable name prefixed with "$",
+// optionally followed by filters that transform the value, each a "|"
+// followed by a filter name, i.e. "cmd/$ProjectName|snake/main.go". Filters
+// are applied in order, see PathFilters. Braces separate a placeholder from
+// text that follows it, i.e. "${Name|lower}_test.go", and "$$" is a literal
+// "$". A placeholder immediately followed by name characters expands the
+// longest variable name that prefixes it, so "$Name_test.go" expands "Name"
+// if there is no "Name_test" variable.
+//
+// An error is returned if a placeholder refers to an undefined variable or
+// filter or if an expanded value contains characters that are not valid in
+// file names on all platforms. Values may contain "/" separators.
+func (self Variables) ExpandPath(in string) (out string, err error) {
+	return expandPlaceholders(in, self.Exists, func(name string, filters []string) (string, error) {
+		var value, exists = self[name]
+		if !exists {
+			return "", fmt.Errorf("path '%s': undefined variable '%s'", in, name)
+		}
+		var s = fmt.Sprint(value)
+		if strings.ContainsAny(s, invalidPathChars) || strings.ContainsFunc(s, unicode.IsControl) {
+			return "", fmt.Errorf("path '%s': variable '%s' value '%s' is not valid in a path", in, name, s)
+		}
+		if s, err = applyPathFilters(s, filters); err != nil {
+			return "", fmt.Errorf("path '%s': %w", in, err)
+		}
+		return s, nil
+	})
 }
 
 // Exists returns true if variable under name exists.
@@ -151,7 +173,7 @@ func (self Variables) Print(wr io.Writer) {
 	if len(self) == 0 {
 		return
 	}
-	fmt.Println("Variables:")
+	fmt.Fprintln(wr, "Variables:")
 	for k, v := range self {
 		fmt.Fprintf(wr, "%s\t%v\n", k, v)
 	}
@@ -0,0 +1,103 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package backup implements boil's backup command.
+package backup
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/vedranvuk/boil/pkg/boil"
+)
+
+// Config is the Backup command configuration.
+type Config struct {
+	// Action is the backup action to perform.
+	// One of "list", "restore" or "prune".
+	Action string
+	// ID is the id of the backup to restore.
+	ID string
+	// Keep is the number of newest backups to keep when pruning.
+	// If empty all backups are kept unless OlderThan is set.
+	Keep string
+	// OlderThan is a duration in time.ParseDuration format. Backups older
+	// than OlderThan are removed when pruning.
+	OlderThan string
+	// Config is the loaded program configuration.
+	Config *boil.Config
+}
+
+// Run executes the Backup command configured by config.
+// If an error occurs it is returned and the operation may be considered failed.
+func Run(config *Config) (err error) {
+
+	var printer = boil.NewPrinter(os.Stdout)
+
+	switch config.Action {
+	case "list":
+		var backups []*boil.Backup
+		if backups, err = boil.ListBackups(); err != nil {
+			return
+		}
+		if len(backups) == 0 {
+			printer.Printf("No backups.\n")
+			return nil
+		}
+		printer.Printf("[ID]\t[Created]\t[Files]\t[Directory]\n")
+		for _, backup := range backups {
+			printer.Printf("%s\t%s\t%d\t%s\n",
+				backup.ID,
+				backup.Created.Format(time.DateTime),
+				len(backup.Files),
+				backup.Dir,
+			)
+		}
+	case "restore":
+		if config.ID == "" {
+			return fmt.Errorf("no backup id specified")
+		}
+		if err = boil.RestoreBackup(config.ID); err != nil {
+			return fmt.Errorf("restore backup: %w", err)
+		}
+		if config.Config.Overrides.Verbose {
+			printer.Printf("Restored backup %s\n", config.ID)
+		}
+	case "prune":
+		var (
+			keep   = -1
+			before time.Time
+			pruned []*boil.Backup
+		)
+		if config.Keep != "" {
+			if keep, err = strconv.Atoi(config.Keep); err != nil || keep < 0 {
+				return fmt.Errorf("invalid keep value: %s", config.Keep)
+			}
+		}
+		if config.OlderThan != "" {
+			var d time.Duration
+			if d, err = time.ParseDuration(config.OlderThan); err != nil {
+				return fmt.Errorf("invalid older-than value: %w", err)
+			}
+			before = time.Now().Add(-d)
+		}
+		if keep < 0 && before.IsZero() {
+			return fmt.Errorf("prune requires keep or older-than")
+		}
+		if pruned, err = boil.PruneBackups(keep, before); err != nil {
+			return fmt.Errorf("prune backups: %w", err)
+		}
+		if config.Config.Overrides.Verbose {
+			for _, backup := range pruned {
+				printer.Printf("Removed backup %s\n", backup.ID)
+			}
+		}
+	default:
+		panic("unknown backup action")
+	}
+
+	return nil
+}
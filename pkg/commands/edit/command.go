@@ -108,7 +108,7 @@ func Run(config *Config) (err error) {
 			return
 		}
 		for _, entry := range state.meta.Files {
-			if strings.EqualFold(entry, config.EditTarget) {
+			if strings.EqualFold(entry.Path, config.EditTarget) {
 				entryExists = true
 				break
 			}
@@ -8,12 +8,12 @@ package exec
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/vedranvuk/bast/pkg/bast"
 	"github.com/vedranvuk/boil/pkg/boil"
 )
 
@@ -26,7 +26,9 @@ type Config struct {
 	// absolute path to a Template and no repository is being loaded or used.
 	//
 	// If the path is not rooted, the path is treated as a path to a Template
-	// relative to the loaded repository.
+	// relative to a repository in the repository search path. It may be
+	// prefixed with a repository name and a colon to use a specific
+	// repository, i.e. "team:apps/app".
 	//
 	// If TemplatePath is an absolute filesystem path it is adjusted to an
 	// empty string during Run().
@@ -44,15 +46,49 @@ type Config struct {
 	// prompting the user or generating an error.
 	Overwrite bool
 
+	// OnConflict is the name of the boil.ConflictPolicy for output files that
+	// already exist and are not matched by a conflict rule in the Template
+	// metafile. If empty, execution fails on existing files.
+	OnConflict string
+
 	// NoExecute if true will not execute any write operations and will
 	// instead print out the operations like boil.Config.Verbose was enabled.
+	//
+	// Template files are fully executed into memory over the output
+	// directory, actions are printed instead of being run and a report of the
+	// files that would be written is printed.
 	NoExecute bool
 
+	// Diff if true executes Template files into memory like NoExecute and
+	// prints a unified diff of each output file against the file in the
+	// output directory instead of writing output. Files are reported as new,
+	// modified or unchanged. If OnConflict is empty existing files are
+	// diffed as if overwritten.
+	Diff bool
+
 	// NoPrompts if true disables prompting the user for variables and will
 	// return an error if a variable declared in a prompt was not parsed from
 	// the command line.
 	NoPrompts bool
 
+	// NonInteractive if true never prompts the user. Prompts not answered by
+	// Vars or Answers get their default values and execution fails if a
+	// required prompt has no value. Conflict policy prompts fail as well.
+	NonInteractive bool
+
+	// Answers is an optional path to a JSON or YAML file with prompt answers.
+	// See boil.LoadAnswers. Vars take precedence over Answers.
+	Answers string
+
+	// Record is an optional path of a JSON file to save prompt answers of
+	// the execution to. See boil.Session.
+	Record string
+
+	// Replay is an optional path of a JSON file with prompt answers saved
+	// using Record. Prompts are answered from the file instead of asking the
+	// user and execution fails if the file has no answer for a prompt.
+	Replay string
+
 	// NoMetadata if true disables parsing template metadata and copies the
 	// source template files recursively to output directory. This disables
 	// groups and prompts but the variable system still works via command line.
@@ -73,7 +109,25 @@ type Config struct {
 	// These variables will be available via .Vars template field.
 	Vars boil.Variables
 
+	// Manifest if true writes a boil.Manifest of the execution to the output
+	// directory along with base copies of generated files so the output can
+	// later be upgraded to a newer Template version.
+	Manifest bool
+
+	// Previous, if not nil, is the Manifest of a previous execution into the
+	// output directory. It is used to upgrade output to a newer Template
+	// version. Merges use base copies it records.
+	Previous *boil.Manifest
+
+	// Repository, if not nil, is the Repository to execute the Template from.
+	// TemplatePath is then a path to the Template inside Repository and the
+	// repositories defined in Config are not used. This allows executing
+	// Templates from any Repository implementation, i.e. an FSRepository
+	// serving templates embedded in a program.
+	Repository boil.Repository
+
 	// Config is the loaded program configuration.
+	// If nil, a default configuration is used.
 	Config *boil.Config
 }
 
@@ -87,285 +141,252 @@ func (self *Config) GetRepositoryPath() string {
 	return self.Config.GetRepositoryPath()
 }
 
-// state maintains exec command execution.
-// It's passed around the files in this package.
-type state struct {
-	RepositoryPath string
-	// TemplatePath is the adjusted path to the template usable by Repository.
-	TemplatePath string
-	// OutputDir is the adjusted absolute path to the output directory.
-	OutputDir string
-	// Repository is the loaded Repository.
-	Repository boil.Repository
-	// Data for Template files, combined from various inputs.
-	Data *boil.Data
-	// MakeBackup dictates if backups should be made on execution.
-	MakeBackups bool
-	// Tasks are the Tasks to execute.
-	Tasks Tasks
-}
-
 // Run executes the Exec command configured by config.
 // If an error occurs it is returned and the operation may be considered failed.
+//
+// Run determines the Repository and the Template path from config and
+// executes the Template using a boil.Executor.
 func Run(config *Config) (err error) {
 
 	var printer = boil.NewPrinter(os.Stdout)
 
+	if config.Config == nil {
+		if config.Config, err = boil.DefaultConfig(); err != nil {
+			return fmt.Errorf("default config: %w", err)
+		}
+	}
 	if config.NoExecute {
 		printer.Printf("NoExecute enabled, printing commands instead of executing.\n")
 	}
 
-	// Init state to Config values.
-	var state = &state{
-		RepositoryPath: config.GetRepositoryPath(),
-		TemplatePath:   config.TemplatePath,
-		OutputDir:      config.OutputDir,
-		MakeBackups:    config.Config.ShouldBackup(),
-		Data:           boil.NewData(),
+	var (
+		repo     boil.Repository
+		tmplPath string
+		// tmplRef is the Template reference recorded in a Manifest.
+		tmplRef string
+		verbose *boil.Printer
+	)
+	if config.ShouldPrint() {
+		verbose = printer
+	}
+	if repo, tmplPath, tmplRef, err = resolveTemplate(config, verbose); err != nil {
+		return
 	}
 
-	// Determine repository and template paths then open repository.
-	if !boil.IsRepoPath(config.TemplatePath) || config.Config.Overrides.NoRepository {
-		// If TemplatePath is an absolute path or no repository use is forced
-		// open the Template directory as Repository and adjust the template
-		// path to "current directory" pointing to repository root.
-		if path, group, found := strings.Cut(config.TemplatePath, "#"); found {
-			state.TemplatePath = ".#" + group
-			state.RepositoryPath = path
-		} else {
-			state.TemplatePath = "."
-			state.RepositoryPath = path
-		}
-		if config.ShouldPrint() && config.Config.Overrides.NoRepository {
-			printer.Printf("No repository mode.\n")
+	// Load answers, vars given on the command line take precedence.
+	var vars = make(boil.Variables).AddNew(config.Vars)
+	if config.Answers != "" {
+		var answers boil.Variables
+		if answers, err = boil.LoadAnswers(config.Answers); err != nil {
+			return
 		}
+		vars.AddNew(answers)
 	}
-	if state.Repository, err = boil.OpenRepository(state.RepositoryPath); err != nil {
-		return fmt.Errorf("open repository: %w", err)
+
+	// Configure the executor.
+	var executor = boil.NewExecutor(config.Config, repo)
+	executor.Vars = vars
+	executor.GoInputs = config.GoInputs
+	executor.JsonInputs = config.JsonInputs
+	executor.NoMetadata = config.NoMetadata
+	executor.Overwrite = config.Overwrite
+	if executor.OnConflict, err = boil.ParseConflictPolicy(config.OnConflict); err != nil {
+		return
 	}
-	// Determine absolute output path.
-	if state.OutputDir, err = filepath.Abs(config.OutputDir); err != nil {
-		return fmt.Errorf("get absolute output path: %w", err)
+	if config.NoPrompts || config.NonInteractive {
+		executor.Prompter = nil
+		executor.Resolver = nil
 	}
-	// Produce execution tasks depending on execution mode.
-	switch config.NoMetadata {
-	case false:
-		// Create a Template list, it will contain only the source paths of all
-		// referenced template file paths over all referenced templates in a
-		// possible group. Outputs are determined later after all variables have
-		// been loaded.
-		if state.Tasks, err = tasksFromMetafile(state.Repository, state.TemplatePath); err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				return fmt.Errorf("not a boil template: %s", config.TemplatePath)
-			}
-			return fmt.Errorf("enumerate template files for execution: %w", err)
-		}
-	case true:
-		if state.Tasks, err = tasksFromWalk(state.Repository, state.TemplatePath); err != nil {
-			return fmt.Errorf("enumerate template files for execution: %w", err)
+	executor.NonInteractive = config.NonInteractive
+	if config.Replay != "" {
+		var session *boil.Session
+		if session, err = boil.LoadSession(config.Replay); err != nil {
+			return
 		}
+		executor.Prompter = boil.NewReplayPrompter(session)
+		executor.NonInteractive = false
 	}
-	// Exec pre parse actions.
-	if err = state.Tasks.ExecPreParseActions(); err != nil {
-		return fmt.Errorf("pre parse action failed: %w", err)
+	if config.Record != "" {
+		executor.Record = &boil.Session{TemplatePath: tmplRef}
 	}
-	// Load Data.
-	if state.Data, err = boil.DataFromInputs(config.Vars, config.GoInputs, config.JsonInputs); err != nil {
-		return fmt.Errorf("load data: %w", err)
+	if config.Manifest {
+		executor.Manifest = &boil.Manifest{TemplatePath: tmplRef}
 	}
-
-	// TODO: Clean up variable expansion.
-
-	// Set vars declared on command line as initial state vars before showing
-	// prompts so variables declared in prompts that have had their value set
-	// early via command line will not be prompted for.
-	state.Data.Vars[boil.VarOutputDir.String()] = state.OutputDir
-	state.Data.Vars[boil.VarTemplatePath.String()] = state.TemplatePath
-	state.Data.Vars[boil.VarAuthorName.String()] = config.Config.Author.Name
-	state.Data.Vars[boil.VarAuthorEmail.String()] = config.Config.Author.Email
-	state.Data.Vars[boil.VarAuthorHomepage.String()] = config.Config.Author.Homepage
-	if !config.NoPrompts && !config.NoMetadata {
-		if err = state.Tasks.PresentPrompts(
-			state,
-			func(p *boil.Prompt) (def string, present bool) {
-				present = true
-				switch p.Variable {
-				case boil.VarProjectName.String():
-					// Set default value to base of output dir in vars.
-					// if current dir, set from state.
-					// if again current dir, use a magic name.
-					if def = filepath.Base(state.Data.StringVar(boil.VarOutputDir.String())); def != "" && def != "." {
-						return
-					}
-					if def = filepath.Base(state.OutputDir); def == "." {
-						def = "new_project"
-					}
-				case boil.VarModulePath.String():
-					// Set default value to config.ModulePrefix + ProjectName.
-					if def = state.Data.StringVar(boil.VarProjectName.String()); def == "" || def == "." {
-						if def = filepath.Base(state.OutputDir); def == "." {
-							def = "new_project"
-						}
-					}
-					def = filepath.Join(config.Config.Author.ModulePrefix, def)
-				case boil.VarOutputDir.String():
-					def = state.OutputDir
-				}
-				return
-			},
-		); err != nil {
-			return fmt.Errorf("prompt user: %w", err)
-		}
+	executor.Previous = config.Previous
+	if config.ShouldPrint() {
+		executor.Logger = os.Stdout
 	}
-	// If OutputDir was set via Prompt update state.OutputDir.
-	if state.Data.Vars.Exists(boil.VarOutputDir.String()) {
-		if state.Data.Vars[boil.VarOutputDir.String()], err = filepath.Abs(state.Data.StringVar(boil.VarOutputDir.String())); err != nil {
-			return fmt.Errorf("state output dir: %w", err)
+	var overlay *boil.OverlayFS
+	if config.NoExecute || config.Diff {
+		overlay = boil.NewOverlayFS(executor.Output)
+		executor.Output = overlay
+		if config.Diff && config.OnConflict == "" {
+			executor.OnConflict = boil.ConflictOverwrite
 		}
-		state.OutputDir = state.Data.StringVar(boil.VarOutputDir.String())
-	}
-	// Optionally print Bast.
-	if config.ShouldPrint() && len(state.Data.Bast.Packages) > 0 {
-		printer.Printf("Go input:\n")
-		bast.Print(os.Stdout, state.Data.Bast)
-	}
-	// Now that the vars have been loaded expand variable placeholders in
-	// template paths.
-	if err = state.Tasks.SetTargetsFromState(state); err != nil {
-		return fmt.Errorf("expand target file names: %w", err)
+		executor.Runner = boil.ActionRunnerFunc(func(action *boil.Action, data *boil.Data) error {
+			printer.Printf("Action: %s %s\n", action.Program, strings.Join(action.Arguments, " "))
+			return nil
+		})
 	}
-	// Validate templates and optionally check for output conflicts.
-	if !config.NoMetadata {
-		if err = state.Tasks.Validate(state); err != nil {
-			return fmt.Errorf("validation failed: %w", err)
-		}
+
+	// Execute the template then optionally open output directory in external
+	// editor.
+	if err = executor.Execute(tmplPath, config.OutputDir); err != nil {
+		return
 	}
-	if !config.Overwrite {
-		if err = state.Tasks.CheckForTargetConflicts(); err != nil {
-			return err
+	if executor.Record != nil {
+		if err = executor.Record.Save(config.Record); err != nil {
+			return
 		}
 	}
-	// Optional verbose output.
-	if config.ShouldPrint() {
-		printer.Printf("Repository location: %s\n", state.Repository.Location())
-		state.Tasks.Print(printer)
-		state.Data.Vars.Print(printer)
-	}
-	// Exec Pre actions, templates then Post actions. Optionally open output
-	// directory in external editor.
-	if err = state.Tasks.ExecPreExecuteActions(state.Data); err != nil {
-		return fmt.Errorf("pre execute action failed: %w", err)
+	for _, target := range executor.Conflicted {
+		printer.Printf("Warning: %s contains merge conflicts.\n", target)
 	}
-	if err = state.Tasks.Execute(state, config.ShouldPrint()); err != nil {
-		return
+	if config.Diff {
+		return printDiff(os.Stdout, executor.OutputDir, overlay)
 	}
-	if err = state.Tasks.ExecPostExecuteActions(state.Data); err != nil {
-		return fmt.Errorf("post execute action failed: %w", err)
+	if config.NoExecute {
+		return printReport(printer, executor.OutputDir, overlay)
 	}
 	if config.EditAfterExec {
-		state.Data.Vars.AddNew(boil.Variables{
-			boil.VarEditTarget.String(): state.OutputDir,
+		executor.Data.Vars.AddNew(boil.Variables{
+			boil.VarEditTarget.String(): executor.OutputDir,
 		})
-		if err = config.Config.Editor.Execute(state.Data); err != nil {
+		if err = config.Config.Editor.Execute(executor.Data); err != nil {
 			return
 		}
 	}
 	return nil
 }
 
-// tasksFromMetafile returns Templates to be executed from a state. It
-// returns empty Templates and an error if the state is invalid, one or more
-// template files is missing, any group addresses a missing template or some
-// other error.
-func tasksFromMetafile(repo boil.Repository, path string) (templates Tasks, err error) {
-	err = produceTasksFromMetafile(repo, path, &templates)
-	return
-}
-
-// produceTasksFromMetafile uses state to recursively construct execs starting
-// from path. if the function failes it returns an error.
-func produceTasksFromMetafile(repo boil.Repository, path string, out *Tasks) (err error) {
-
-	var (
-		meta   *boil.Metafile
-		group  string
-		exists bool
-	)
-
-	path, group, _ = strings.Cut(path, "#")
-
-	if meta, err = repo.OpenMeta(path); err != nil {
-		return err
+// printReport prints directories and files written to the upper layer of
+// overlay along with file sizes and their change status against the lower
+// layer. The manifest written to outputDir is not reported.
+func printReport(printer *boil.Printer, outputDir string, overlay *boil.OverlayFS) (err error) {
+	printer.Printf("Output:\n")
+	printer.Printf("[Target]\t[Size]\t[Status]\n")
+	for _, dir := range overlay.Upper().Dirs() {
+		if boil.IsManifestPath(outputDir, dir) {
+			continue
+		}
+		if _, err = overlay.Lower().Stat(dir); err == nil {
+			continue
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("stat output dir: %w", err)
+		}
+		printer.Printf("%s\t-\tnew dir\n", dir)
 	}
-
-	var template = &Task{
-		Metafile: meta,
+	var changes []*boil.FileChange
+	if changes, err = overlay.Changes(); err != nil {
+		return fmt.Errorf("compare output: %w", err)
 	}
-
-	for _, dir := range meta.Directories {
-		template.List = append(template.List, &Execute{
-			Path:   dir,
-			Source: filepath.Join(path, dir),
-			IsDir:  true,
-		})
+	for _, change := range changes {
+		if boil.IsManifestPath(outputDir, change.Path) {
+			continue
+		}
+		printer.Printf("%s\t%d\t%s\n", change.Path, len(change.New), change.Status)
 	}
+	return nil
+}
 
-	for _, file := range meta.Files {
-		if exists, err = repo.Exists(filepath.Join(path, file)); err != nil {
-			return err
+// printDiff writes a unified diff of each file written to the upper layer of
+// overlay against the lower layer to w. File names are relative to
+// outputDir. Unchanged files are listed without a diff. The manifest written
+// to outputDir is not listed.
+func printDiff(w io.Writer, outputDir string, overlay *boil.OverlayFS) (err error) {
+	var changes []*boil.FileChange
+	if changes, err = overlay.Changes(); err != nil {
+		return fmt.Errorf("compare output: %w", err)
+	}
+	for _, change := range changes {
+		if boil.IsManifestPath(outputDir, change.Path) {
+			continue
 		}
-		if !exists {
-			return fmt.Errorf("template file '%s' does not exist", filepath.Join(path, file))
+		var name string
+		if name, err = filepath.Rel(outputDir, change.Path); err != nil {
+			name = change.Path
+		}
+		name = filepath.ToSlash(name)
+		fmt.Fprintf(w, "%s: %s\n", change.Status, name)
+		if change.Status == boil.ChangeUnchanged {
+			continue
+		}
+		if err = boil.WriteUnifiedDiff(w, "a/"+name, "b/"+name, change.Old, change.New); err != nil {
+			return fmt.Errorf("write diff: %w", err)
 		}
-		template.List = append(template.List, &Execute{
-			Path:   file,
-			Source: filepath.Join(path, file),
-			IsDir:  false,
-		})
 	}
+	return nil
+}
 
-	*out = append(*out, template)
-
-	if group != "" {
-		for _, g := range meta.Groups {
-			if g.Name == group {
-				continue
-			}
-			for _, name := range g.Templates {
-				if err = produceTasksFromMetafile(repo, filepath.Join(path, name), out); err != nil {
-					return
-				}
-			}
+// ResolveTemplate returns the Repository that contains the Template at
+// config.TemplatePath and the path of the Template inside it like Run
+// determines them or an error.
+func ResolveTemplate(config *Config) (repo boil.Repository, tmplPath string, err error) {
+	if config.Config == nil {
+		if config.Config, err = boil.DefaultConfig(); err != nil {
+			return nil, "", fmt.Errorf("default config: %w", err)
 		}
 	}
-
-	return nil
+	repo, tmplPath, _, err = resolveTemplate(config, nil)
+	return
 }
 
-// tasksFromWalk returns Templates to be executed from walking the repo starting
-// at the root directory or an error if one occured.
-// It returns a single tasks that holds all Execs.
-func tasksFromWalk(repo boil.Repository, root string) (out Tasks, err error) {
-	var task = new(Task)
-	if err = repo.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
-		if root == path {
-			return nil
+// resolveTemplate determines and opens the Repository and the Template path
+// inside it from config. It also returns the Template reference recorded in a
+// Manifest. If printer is not nil the used repository is printed to it.
+func resolveTemplate(config *Config, printer *boil.Printer) (repo boil.Repository, tmplPath, tmplRef string, err error) {
+
+	var repoPath = config.GetRepositoryPath()
+	tmplPath, tmplRef = config.TemplatePath, config.TemplatePath
+
+	// Determine repository and template paths then open repository.
+	if config.Repository != nil {
+		// Use the given repository.
+		repo = config.Repository
+	} else if !boil.IsRepoPath(config.TemplatePath) || config.Config.Overrides.NoRepository {
+		// If TemplatePath is an absolute path or no repository use is forced
+		// open the Template directory as Repository and adjust the template
+		// path to "current directory" pointing to repository root.
+		if path, group, found := strings.Cut(config.TemplatePath, "#"); found {
+			tmplPath = ".#" + group
+			repoPath = path
+		} else {
+			tmplPath = "."
+			repoPath = path
+		}
+		if printer != nil && config.Config.Overrides.NoRepository {
+			printer.Printf("No repository mode.\n")
+		}
+		if repo, err = boil.OpenRepository(repoPath); err != nil {
+			return nil, "", "", fmt.Errorf("open repository: %w", err)
 		}
+		if tmplRef, err = filepath.Abs(config.TemplatePath); err != nil {
+			return nil, "", "", fmt.Errorf("get absolute template path: %w", err)
+		}
+	} else {
+		// Otherwise resolve the template in the repository search path.
 		var (
-			exe = new(Execute)
-			rel string
+			repos boil.Repositories
+			named *boil.NamedRepository
 		)
-		if rel, err = filepath.Rel(root, path); err != nil {
-			return err
+		if repos, err = boil.OpenRepositories(config.Config); err != nil {
+			return nil, "", "", fmt.Errorf("open repositories: %w", err)
+		}
+		if config.NoMetadata {
+			named, tmplPath, err = repos.ResolveDir(config.TemplatePath)
+		} else {
+			named, tmplPath, err = repos.Resolve(config.TemplatePath)
+		}
+		if err != nil {
+			if errors.Is(err, boil.ErrTemplateNotFound) {
+				return nil, "", "", fmt.Errorf("not a boil template: %s", config.TemplatePath)
+			}
+			return nil, "", "", fmt.Errorf("resolve template: %w", err)
+		}
+		repo = named.Repository
+		if printer != nil {
+			printer.Printf("Using template %s from repository '%s'.\n", tmplPath, named.Name)
 		}
-		exe.Path = rel
-		exe.Source = path
-		exe.IsDir = d.IsDir()
-		task.List = append(task.List, exe)
-		return nil
-	}); err != nil {
-		return nil, fmt.Errorf("enumerate source files: %w", err)
 	}
-	out = append(out, task)
 	return
 }
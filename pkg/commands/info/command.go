@@ -23,13 +23,15 @@ type Config struct {
 
 // Run executes the Info command configured by config.
 // If an error occurs it is returned and the operation may be considered failed.
+//
+// If the Template extends another Template the merged Metafile is printed.
 func Run(config *Config) (err error) {
 
 	var (
 		repo     boil.Repository
+		repoName string
 		meta     *boil.Metafile
 		printer  = boil.NewPrinter(os.Stdout)
-		repoPath = config.Config.GetRepositoryPath()
 		tmplPath string
 	)
 
@@ -38,21 +40,40 @@ func Run(config *Config) (err error) {
 		// If TemplatePath is an absolute path open the Template as the
 		// Repository and adjust the template path to "current directory"
 		// pointing to repository root.
-		repoPath = tmplPath
+		if repo, err = boil.OpenRepository(tmplPath); err != nil {
+			return fmt.Errorf("open repository: %w", err)
+		}
 		tmplPath = "."
 		if config.Config.Overrides.Verbose {
 			printer.Printf("Absolute Template path specified, repository opened at template root.")
 		}
+	} else {
+		// Resolve the template in the repository search path.
+		var (
+			repos boil.Repositories
+			named *boil.NamedRepository
+		)
+		if repos, err = boil.OpenRepositories(config.Config); err != nil {
+			return fmt.Errorf("open repositories: %w", err)
+		}
+		if named, tmplPath, err = repos.Resolve(config.TemplatePath); err != nil {
+			return err
+		}
+		tmplPath, _, _ = strings.Cut(tmplPath, "#")
+		repo, repoName = named.Repository, named.Name
 	}
 
-	// Open repository and get its metamap, check template exists.
-	if repo, err = boil.OpenRepository(repoPath); err != nil {
-		return fmt.Errorf("open repository: %w", err)
-	}
 	if meta, err = repo.OpenMeta(tmplPath); err != nil {
 		return fmt.Errorf("template %s not found", config.TemplatePath)
 	}
+	// Show the Metafile merged with the Templates it extends.
+	if meta, err = boil.ResolveExtends(repo, meta); err != nil {
+		return fmt.Errorf("resolve template %s: %w", config.TemplatePath, err)
+	}
 
+	if repoName != "" {
+		printer.Printf("Repository:\t%s (%s)\n", repoName, repo.Location())
+	}
 	meta.Print(printer)
 
 	return nil
@@ -0,0 +1,95 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package lint implements boil's lint command.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/vedranvuk/boil/pkg/boil"
+)
+
+// Config is the Lint command configuration.
+type Config struct {
+	// Repository is the name of a repository in the repository search path
+	// to lint. If empty and a repository was given on command line only that
+	// repository is linted, otherwise all repositories in the search path
+	// are linted.
+	Repository string
+	// JSON if true prints issues as a JSON array instead of text lines.
+	JSON bool
+	// Config is the loaded program configuration.
+	Config *boil.Config
+}
+
+// Run executes the Lint command configured by config.
+// If an error occurs it is returned and the operation may be considered failed.
+//
+// Run prints all issues found by boil.Lint in the linted repositories and
+// returns an error if any of them is an error, so it can be used from a
+// pre-commit hook. Warnings alone do not fail the command.
+func Run(config *Config) (err error) {
+
+	var (
+		repos  boil.Repositories
+		issues = boil.LintIssues{}
+	)
+
+	if repos, err = boil.OpenRepositories(config.Config); err != nil {
+		return fmt.Errorf("open repositories: %w", err)
+	}
+	var name = config.Repository
+	if name == "" && config.Config.Overrides.RepositoryPath != "" {
+		name = boil.OverrideRepositoryName
+	}
+	if name != "" {
+		var named = repos.Find(name)
+		if named == nil {
+			return fmt.Errorf("repository '%s' not found", name)
+		}
+		repos = boil.Repositories{named}
+	}
+
+	for _, repo := range repos {
+		if err = repo.Open(); err != nil {
+			return err
+		}
+		// Always lint in strict mode so misspelled fields are reported.
+		if strict, ok := repo.Repository.(boil.StrictRepository); ok {
+			strict.SetStrict(true)
+		}
+		var found boil.LintIssues
+		if found, err = boil.Lint(repo.Repository); err != nil {
+			return fmt.Errorf("lint repository '%s': %w", repo.Location(), err)
+		}
+		for _, issue := range found {
+			issue.Repository = repo.Name
+		}
+		issues = append(issues, found...)
+	}
+
+	if config.JSON {
+		var enc = json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err = enc.Encode(issues); err != nil {
+			return fmt.Errorf("encode issues: %w", err)
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue.String())
+		}
+	}
+
+	if n := issues.Errors(); n > 0 {
+		return fmt.Errorf("lint found %d error(s) and %d warning(s)", n, len(issues)-n)
+	}
+	if !config.JSON && config.Config.Overrides.Verbose {
+		fmt.Printf("Lint found %d warning(s).\n", len(issues))
+	}
+
+	return nil
+}
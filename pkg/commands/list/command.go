@@ -8,6 +8,7 @@ package list
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/vedranvuk/boil/pkg/boil"
@@ -15,7 +16,9 @@ import (
 
 // Config is the List command configuration.
 type Config struct {
-	// Prefix is the path prefix at which to start listing.
+	// Prefix is the path prefix at which to start listing. It may be
+	// prefixed with a repository name and a colon to list only templates
+	// from that repository.
 	Prefix string
 	// Config is the loaded program configuration.
 	Config *boil.Config
@@ -26,35 +29,74 @@ type Config struct {
 func Run(config *Config) (err error) {
 
 	var (
-		repo    boil.Repository
+		repos   boil.Repositories
 		meta    boil.Metamap
-		list    = make(boil.Metamap)
+		list    []listEntry
 		printer = boil.NewPrinter(os.Stdout)
+		prefix  = config.Prefix
+		search  boil.Repositories
 	)
 
-	if repo, err = boil.OpenRepository(config.Config.GetRepositoryPath()); err != nil {
-		return fmt.Errorf("open repository: %w", err)
+	if repos, err = boil.OpenRepositories(config.Config); err != nil {
+		return fmt.Errorf("open repositories: %w", err)
 	}
-	if meta, err = repo.LoadMetamap(); err != nil {
-		return fmt.Errorf("load metamap: %w", err)
+
+	// A prefix may be prefixed with a repository name to list only that
+	// repository.
+	search = repos
+	if name, rest, found := strings.Cut(prefix, ":"); found {
+		if repo := repos.Find(name); repo != nil {
+			search, prefix = boil.Repositories{repo}, rest
+		}
 	}
 
-	for k, v := range meta {
-		if k = strings.ToLower(k); strings.HasPrefix(k, strings.ToLower(config.Prefix)) {
-			list[k] = v
+	for _, repo := range search {
+		if err = repo.Open(); err != nil {
+			return err
+		}
+		if meta, err = repo.LoadMetamap(); err != nil {
+			return fmt.Errorf("load metamap of repository '%s': %w", repo.Name, err)
+		}
+		for k, v := range meta {
+			if strings.HasPrefix(strings.ToLower(k), strings.ToLower(prefix)) {
+				list = append(list, listEntry{k, repo.Name, v})
+			}
 		}
 	}
 	if len(list) == 0 {
 		printer.Printf("No templates in repository.\n")
 		return nil
 	}
+
+	// Sort by path, then by repository precedence which is retained by a
+	// stable sort. Templates shadowed by a template with the same path in
+	// a repository of higher precedence are marked.
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+
 	if config.Prefix != "" {
-		printer.Printf("Templates found in current repository at %s:\n", config.Prefix)
+		printer.Printf("Templates found in repositories at %s:\n", config.Prefix)
 	} else {
-		printer.Printf("Templates found in current repository:\n")
+		printer.Printf("Templates found in repositories:\n")
 	}
 	printer.Printf("\n")
-	list.Print(printer)
+	printer.Printf("[Template]\t[Repository]\t[Description]\n")
+	for i, entry := range list {
+		var repo = entry.Repository
+		if i > 0 && list[i-1].Path == entry.Path {
+			repo += " (shadowed)"
+		}
+		printer.Printf("%s\t%s\t%s\n", entry.Path, repo, entry.Metafile.Description)
+	}
 
 	return nil
 }
+
+// listEntry is a template found in a repository.
+type listEntry struct {
+	// Path is the template path.
+	Path string
+	// Repository is the name of the repository that contains the template.
+	Repository string
+	// Metafile is the template Metafile.
+	Metafile *boil.Metafile
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package schema implements boil's schema command.
+package schema
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vedranvuk/boil/pkg/boil"
+)
+
+// Config is the Schema command configuration.
+type Config struct {
+	// Document is the name of the document to print the schema of,
+	// "metafile" or "config".
+	Document string
+	// Config is the loaded program configuration.
+	Config *boil.Config
+}
+
+// Run executes the Schema command configured by config.
+// If an error occurs it is returned and the operation may be considered failed.
+//
+// Run prints the JSON Schema of the document to stdout.
+func Run(config *Config) (err error) {
+
+	var schema boil.Schema
+	switch config.Document {
+	case "metafile":
+		schema = boil.MetafileSchema()
+	case "config":
+		schema = boil.ConfigSchema()
+	default:
+		return fmt.Errorf("invalid document '%s', must be 'metafile' or 'config'", config.Document)
+	}
+
+	var data []byte
+	if data, err = schema.MarshalIndent(); err != nil {
+		return fmt.Errorf("marshal schema: %w", err)
+	}
+	if _, err = fmt.Fprintf(os.Stdout, "%s\n", data); err != nil {
+		return fmt.Errorf("write schema: %w", err)
+	}
+
+	return nil
+}
@@ -95,20 +95,20 @@ func Run(config *Config) (err error) {
 			if path, err = filepath.Rel(source, path); err != nil {
 				return err
 			}
-			if path == "." || path == strings.ToLower(boil.MetafileName) {
+			if path == "." || boil.IsMetafileName(path) {
 				return nil
 			}
 			if d.IsDir() {
-				meta.Directories = append(meta.Directories, path)
+				meta.Directories = append(meta.Directories, &boil.Entry{Path: path})
 			} else {
-				meta.Files = append(meta.Files, path)
+				meta.Files = append(meta.Files, &boil.Entry{Path: path})
 			}
 			return nil
 		}); err != nil {
 			return fmt.Errorf("enumerate source directory: %w", err)
 		}
 	} else {
-		meta.Files = append(meta.Files, source)
+		meta.Files = append(meta.Files, &boil.Entry{Path: source})
 	}
 
 	// Optional template wizard then save.
@@ -125,11 +125,11 @@ func Run(config *Config) (err error) {
 	if !config.Overwrite {
 		var exists bool
 		for _, file := range meta.Files {
-			if exists, err = repo.Exists(file); err != nil {
+			if exists, err = repo.Exists(file.Path); err != nil {
 				return err
 			}
 			if exists {
-				return fmt.Errorf("template file '%s' already exists", file)
+				return fmt.Errorf("template file '%s' already exists", file.Path)
 			}
 		}
 	}
@@ -146,8 +146,8 @@ func Run(config *Config) (err error) {
 	}
 
 	// Create template directories
-	for _, dir := range meta.Directories {
-		dir = filepath.Join(tmplPath, dir)
+	for _, entry := range meta.Directories {
+		var dir = filepath.Join(tmplPath, entry.Path)
 		if config.Config.Overrides.Verbose {
 			printer.Printf("Create template directory: '%s'\n", dir)
 		}
@@ -157,11 +157,11 @@ func Run(config *Config) (err error) {
 	}
 
 	// Create and copy template files
-	for _, file := range meta.Files {
+	for _, entry := range meta.Files {
 		var (
 			data  []byte
-			inFn  = filepath.Join(source, file)
-			outFn = filepath.Join(tmplPath, file)
+			inFn  = filepath.Join(source, entry.Path)
+			outFn = filepath.Join(tmplPath, entry.Path)
 		)
 		if config.Config.Overrides.Verbose {
 			printer.Printf("Copy %s to %s\n", inFn, outFn)
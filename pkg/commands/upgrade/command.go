@@ -0,0 +1,180 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package upgrade implements boil's upgrade command.
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vedranvuk/boil/pkg/boil"
+	"github.com/vedranvuk/boil/pkg/commands/exec"
+)
+
+// Config is the Upgrade command configuration.
+type Config struct {
+	// OutputDir is the directory that contains output of a previous Template
+	// execution along with its boil.Manifest. If empty, current working
+	// directory is used.
+	OutputDir string
+
+	// TemplatePath, if not empty, overrides the Template path recorded in
+	// the Manifest, i.e. if the Template was moved.
+	TemplatePath string
+
+	// OnConflict is the name of the boil.ConflictPolicy for output files that
+	// already exist. If empty, boil.ConflictMerge is used.
+	OnConflict string
+
+	// NoPrompts if true disables prompting the user for values of variables
+	// of prompts that were added to the Template since the last execution and
+	// for conflicts.
+	NoPrompts bool
+
+	// NoExecute if true executes the upgrade into memory and prints a report
+	// instead of writing output. See exec.Config.NoExecute.
+	NoExecute bool
+
+	// Diff if true executes the upgrade into memory and prints a diff instead
+	// of writing output. See exec.Config.Diff.
+	Diff bool
+
+	// Force if true upgrades the output even if the Template version is not
+	// newer than the version recorded in the Manifest.
+	Force bool
+
+	// Vars are variables given by the user on command line. They override
+	// the variables recorded in the Manifest.
+	Vars boil.Variables
+
+	// Config is the loaded program configuration.
+	// If nil, a default configuration is used.
+	Config *boil.Config
+}
+
+// Run executes the Upgrade command configured by config.
+// If an error occurs it is returned and the operation may be considered failed.
+//
+// Run reads the Manifest from the output directory and executes the Template
+// it records again with the same variables. Generated files that were not
+// edited since are replaced, edited files are merged using a three-way merge
+// with the base copies of files recorded with the Manifest and the Manifest is
+// updated.
+//
+// If both the Manifest and the Template declare a version and the Template
+// version is not newer the output is reported as up to date and the Template
+// is not executed unless Force is set.
+func Run(config *Config) (err error) {
+
+	if config.Config == nil {
+		if config.Config, err = boil.DefaultConfig(); err != nil {
+			return fmt.Errorf("default config: %w", err)
+		}
+	}
+
+	var outputDir string
+	if outputDir, err = filepath.Abs(config.OutputDir); err != nil {
+		return fmt.Errorf("get absolute output path: %w", err)
+	}
+
+	var manifest *boil.Manifest
+	if manifest, err = boil.ReadManifest(boil.DiskFS{}, outputDir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("no boil manifest in %s", outputDir)
+		}
+		return fmt.Errorf("read manifest: %w", err)
+	}
+
+	var (
+		tmplPath   = manifest.TemplatePath
+		onConflict = config.OnConflict
+		vars       = make(boil.Variables)
+	)
+	if config.TemplatePath != "" {
+		tmplPath = config.TemplatePath
+	}
+	if onConflict == "" {
+		onConflict = string(boil.ConflictMerge)
+	}
+	vars.AddNew(config.Vars)
+	vars.AddNew(manifest.Vars)
+
+	if !config.Force {
+		var version string
+		if version, err = templateVersion(config.Config, tmplPath); err != nil {
+			return
+		}
+		var newer bool
+		if newer, err = isNewer(version, manifest.Version); err != nil {
+			return
+		}
+		if !newer {
+			boil.NewPrinter(os.Stdout).Printf("%s is already up to date with template %s version %s.\n",
+				outputDir, tmplPath, manifest.Version,
+			)
+			return nil
+		}
+	}
+
+	if config.Config.Overrides.Verbose {
+		boil.NewPrinter(os.Stdout).Printf("Upgrading %s from template %s version %s.\n",
+			outputDir, tmplPath, manifest.Version,
+		)
+	}
+
+	return exec.Run(&exec.Config{
+		TemplatePath: tmplPath,
+		OutputDir:    outputDir,
+		OnConflict:   onConflict,
+		NoPrompts:    config.NoPrompts,
+		NoExecute:    config.NoExecute,
+		Diff:         config.Diff,
+		Vars:         vars,
+		Manifest:     true,
+		Previous:     manifest,
+		Config:       config.Config,
+	})
+}
+
+// templateVersion returns the version declared in the metafile of the
+// Template at tmplPath or an error.
+func templateVersion(config *boil.Config, tmplPath string) (version string, err error) {
+	var (
+		repo boil.Repository
+		path string
+		meta *boil.Metafile
+	)
+	if repo, path, err = exec.ResolveTemplate(&exec.Config{
+		TemplatePath: tmplPath,
+		Config:       config,
+	}); err != nil {
+		return
+	}
+	path, _, _ = strings.Cut(path, "#")
+	if meta, err = repo.OpenMeta(path); err != nil {
+		return "", fmt.Errorf("open template metafile: %w", err)
+	}
+	return meta.Version, nil
+}
+
+// isNewer returns true if version is newer than previous. If either version
+// is empty the versions cannot be compared and true is returned.
+func isNewer(version, previous string) (newer bool, err error) {
+	if version == "" || previous == "" {
+		return true, nil
+	}
+	var current, last boil.SemVer
+	if current, err = boil.ParseSemVer(version); err != nil {
+		return false, fmt.Errorf("template version: %w", err)
+	}
+	if last, err = boil.ParseSemVer(previous); err != nil {
+		return false, fmt.Errorf("manifest version: %w", err)
+	}
+	return current.Compare(last) > 0, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package validate implements boil's validate command.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vedranvuk/boil/pkg/boil"
+)
+
+// Config is the Validate command configuration.
+type Config struct {
+	// TemplatePath is the path of the Template to validate.
+	TemplatePath string
+	// Config is the loaded program configuration.
+	Config *boil.Config
+}
+
+// Run executes the Validate command configured by config.
+// If an error occurs it is returned and the operation may be considered failed.
+//
+// Run loads the Template Metafile in strict mode, validates it and prints
+// each problem found with its location in the Metafile. If any problems were
+// found an error is returned.
+func Run(config *Config) (err error) {
+
+	var (
+		repo     boil.Repository
+		meta     *boil.Metafile
+		printer  = boil.NewPrinter(os.Stdout)
+		tmplPath string
+	)
+
+	tmplPath, _, _ = strings.Cut(config.TemplatePath, "#")
+	if filepath.IsAbs(config.TemplatePath) || config.Config.Overrides.NoRepository {
+		if repo, err = boil.OpenRepository(tmplPath); err != nil {
+			return fmt.Errorf("open repository: %w", err)
+		}
+		tmplPath = "."
+	} else {
+		var (
+			repos boil.Repositories
+			named *boil.NamedRepository
+		)
+		if repos, err = boil.OpenRepositories(config.Config); err != nil {
+			return fmt.Errorf("open repositories: %w", err)
+		}
+		if named, tmplPath, err = repos.Resolve(config.TemplatePath); err != nil {
+			return err
+		}
+		tmplPath, _, _ = strings.Cut(tmplPath, "#")
+		repo = named.Repository
+	}
+
+	// Always validate in strict mode so misspelled fields are reported.
+	if strict, ok := repo.(boil.StrictRepository); ok {
+		strict.SetStrict(true)
+	}
+	if meta, err = repo.OpenMeta(tmplPath); err != nil {
+		return fmt.Errorf("open template %s: %w", config.TemplatePath, err)
+	}
+
+	var errs boil.ValidationErrors
+	if err = meta.Validate(repo); err != nil {
+		if !errors.As(err, &errs) {
+			return fmt.Errorf("validate template: %w", err)
+		}
+		for _, e := range errs {
+			printer.Printf("%s\n", e.Error())
+		}
+		return fmt.Errorf("template %s has %d problem(s)", config.TemplatePath, len(errs))
+	}
+
+	if config.Config.Overrides.Verbose {
+		printer.Printf("Template %s is valid.\n", config.TemplatePath)
+	}
+
+	return nil
+}